@@ -0,0 +1,181 @@
+package artist
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"geomelody/components"
+	"geomelody/utils"
+)
+
+var mbidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type ArtistComponent struct {
+	components.BaseComponent
+}
+
+type Artist interface {
+	GetArtist(*ArtistForm) (*ArtistResponse, error)
+	GetArtistForm() *ArtistForm
+	GetComponentAppError() *utils.AppError
+	SetComponentAppError(int, error)
+}
+
+type ArtistForm struct {
+	Name     string `json:"name"`
+	MBID     string `json:"mbid"`
+	UseCache bool   `json:"use_cache"`
+}
+
+type SimilarArtist struct {
+	Name   string                   `json:"name"`
+	URL    string                   `json:"url"`
+	Images []components.LastFMImage `json:"images"`
+}
+
+type ArtistTag struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type ArtistResponse struct {
+	Name   string                   `json:"name"`
+	MBID   string                   `json:"mbid"`
+	URL    string                   `json:"url"`
+	Images []components.LastFMImage `json:"images"`
+	OnTour bool                     `json:"on_tour"`
+	Stats  struct {
+		Listeners int `json:"listeners"`
+		PlayCount int `json:"play_count"`
+	} `json:"stats"`
+
+	Similar []SimilarArtist `json:"similar"`
+	Tags    []ArtistTag     `json:"tags"`
+
+	Bio struct {
+		Published string `json:"published"`
+		Summary   string `json:"summary"`
+		Content   string `json:"content"`
+	} `json:"bio"`
+}
+
+// GetArtist is used to fetch the full details of the given artist, looked up by name or by MusicBrainz ID.
+// It returns artist data and error.
+func (ac *ArtistComponent) GetArtist(form *ArtistForm) (*ArtistResponse, error) {
+	resp := new(ArtistResponse)
+	var err error
+	var data *LastFMArtistPayload
+	if err = form.Valid(); err != nil {
+		ac.SetComponentAppError(http.StatusBadRequest, err)
+
+		return nil, err
+	}
+
+	key, value := "artist", form.Name
+	if form.MBID != "" {
+		key, value = "mbid", form.MBID
+	}
+
+	cacheKey := fmt.Sprintf("artist:%v:%v", key, strings.ToLower(value))
+	if components.IsRespInCache(form.UseCache, cacheKey, ac.Cache, resp) {
+		return resp, nil
+	}
+
+	if data, err = fetchArtist(ac.ReqCtx, key, value); err != nil {
+		ac.SetComponentAppError(utils.StatusForError(err), err)
+	} else if err = processArtistDetail(data, resp); err != nil {
+		ac.SetComponentAppError(http.StatusNotFound, err)
+	} else {
+		components.CheckAndCacheResp(form.UseCache, cacheKey, ac.Cache, resp)
+	}
+
+	return resp, err
+}
+
+func processArtistDetail(data *LastFMArtistPayload, ar *ArtistResponse) error {
+	artist := data.Artist
+	if artist == nil {
+		return errors.New("artist not found. Please check the input params")
+	}
+
+	ar.Name = artist.Name
+	ar.MBID = artist.MBID
+	ar.URL = artist.URL
+	ar.Images = artist.Image
+	ar.OnTour = artist.OnTour == 1
+
+	if artist.Stats != nil {
+		ar.Stats.Listeners = int(artist.Stats.Listeners)
+		ar.Stats.PlayCount = int(artist.Stats.PlayCount)
+	}
+
+	ar.Similar = make([]SimilarArtist, 0, len(artist.Similar.Artist))
+	for _, similar := range artist.Similar.Artist {
+		ar.Similar = append(ar.Similar, SimilarArtist{
+			Name:   similar.Name,
+			URL:    similar.URL,
+			Images: similar.Image,
+		})
+	}
+
+	ar.Tags = make([]ArtistTag, 0, len(artist.Tags.Tag))
+	ar.Tags = append(ar.Tags, artist.Tags.Tag...)
+
+	if artist.Bio != nil {
+		ar.Bio.Published = artist.Bio.Published
+		ar.Bio.Summary = strings.Replace(artist.Bio.Summary, "\n", ". ", -1)
+		ar.Bio.Content = artist.Bio.Content
+	}
+
+	log.Printf("processed artist detail data")
+
+	return nil
+}
+
+// GetArtistForm is used to create a new artist form instance.
+// It returns artist form instance.
+func (ac *ArtistComponent) GetArtistForm() *ArtistForm {
+	return new(ArtistForm)
+}
+
+// GetComponentAppError is used to retrieve app error from the component struct.
+// It returns app error of the component.
+func (ac *ArtistComponent) GetComponentAppError() *utils.AppError {
+	return ac.AppError
+}
+
+func (ac *ArtistComponent) SetComponentAppError(status int, err error) {
+	ac.AppError = &utils.AppError{
+		Status: status,
+		Error:  err,
+	}
+}
+
+// Valid validates and normalizes the artist form, either the name or the mbid of the artist is required.
+func (f *ArtistForm) Valid() error {
+	// The name is only sent as a query param, so it is not HTML escaped, else names like "Simon & Garfunkel" would not match.
+	f.Name = strings.TrimSpace(f.Name)
+	f.MBID = strings.TrimSpace(f.MBID)
+
+	if f.MBID != "" {
+		if !mbidRegex.MatchString(f.MBID) {
+			return errors.New("`mbid` parameter is invalid")
+		}
+	} else if f.Name == "" {
+		return errors.New("`name` parameter is invalid")
+	}
+
+	return nil
+}
+
+func init() {
+	components.ComponentMap["Artist"] = func(bc *components.BaseComponent) interface{} {
+		c := &ArtistComponent{BaseComponent: *bc}
+
+		return Artist(c)
+	}
+}
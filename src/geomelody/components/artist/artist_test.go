@@ -0,0 +1,255 @@
+package artist
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"geomelody/components"
+	"geomelody/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtistForm_Valid(t *testing.T) {
+	type vars struct {
+		form *ArtistForm
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   *ArtistForm
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should fail when name and mbid are empty",
+			vars: vars{
+				form: &ArtistForm{},
+			},
+			hasErr: true,
+			err:    "`name` parameter is invalid",
+		},
+		{
+			name: "should fail when mbid is not a valid MusicBrainz ID",
+			vars: vars{
+				form: &ArtistForm{
+					MBID: "coldplay",
+				},
+			},
+			hasErr: true,
+			err:    "`mbid` parameter is invalid",
+		},
+		{
+			name: "should success when name is given",
+			vars: vars{
+				form: &ArtistForm{
+					Name: "Coldplay",
+				},
+			},
+		},
+		{
+			name: "should success and keep the ampersand of the name unescaped",
+			vars: vars{
+				form: &ArtistForm{
+					Name: " Simon & Garfunkel ",
+				},
+			},
+			want: &ArtistForm{
+				Name: "Simon & Garfunkel",
+			},
+		},
+		{
+			name: "should success when mbid is given",
+			vars: vars{
+				form: &ArtistForm{
+					MBID: "cc197bad-dc9c-440d-a5b5-d52ba2e14234",
+				},
+			},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+
+			// Run test
+			err := form.Valid()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				if tCase.want != nil {
+					assert.Exactlyf(t, tCase.want, form, "case: %v", tCase)
+				}
+			}
+		})
+	}
+}
+
+func TestArtistComponent_GetArtist(t *testing.T) {
+	type vars struct {
+		component components.BaseComponent
+
+		form *ArtistForm
+
+		headers map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		hasErr bool
+		err    string
+		status int
+	}{
+		{
+			name: "should success to fetch the artist by name",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &ArtistForm{
+					Name: "Coldplay",
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+		},
+		{
+			name: "should success to fetch the artist by mbid",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &ArtistForm{
+					MBID: "cc197bad-dc9c-440d-a5b5-d52ba2e14234",
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+		},
+		{
+			name: "should fail to fetch the artist",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &ArtistForm{
+					Name: "Coldplay",
+				},
+				headers: map[string]string{
+					"x-mock-api": "error_response",
+				},
+			},
+			hasErr: true,
+			err:    "error",
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "should fail with not found when the vendor does not know the artist",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &ArtistForm{
+					Name: "Coldplayy",
+				},
+				headers: map[string]string{
+					"x-mock-api": "not_found",
+				},
+			},
+			hasErr: true,
+			err:    "The artist you supplied could not be found",
+			status: http.StatusNotFound,
+		},
+		{
+			name: "should fail with too many requests when the vendor rate limits",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &ArtistForm{
+					Name: "Coldplay",
+				},
+				headers: map[string]string{
+					"x-mock-api": "rate_limited",
+				},
+			},
+			hasErr: true,
+			err:    "rate limited",
+			status: http.StatusTooManyRequests,
+		},
+		{
+			name: "should fail with bad gateway when the vendor payload does not match the schema",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &ArtistForm{
+					Name: "Coldplay",
+				},
+				headers: map[string]string{
+					"x-mock-api": "invalid_schema",
+				},
+			},
+			hasErr: true,
+			err:    "error while decoding GetArtistInfo vendor API data",
+			status: http.StatusBadGateway,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+			ac := &ArtistComponent{
+				BaseComponent: tCase.vars.component,
+			}
+			ac.AppError = new(utils.AppError)
+			ctx := ac.ReqCtx
+			ctx = context.WithValue(ctx, "x-mock-headers", tCase.vars.headers)
+			ac.ReqCtx = ctx
+
+			// Run test
+			got, err := ac.GetArtist(form)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+					assert.Equalf(t, tCase.status, ac.GetComponentAppError().Status, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Equalf(t, "Coldplay", got.Name, "case: %v", tCase)
+				assert.Equalf(t, "cc197bad-dc9c-440d-a5b5-d52ba2e14234", got.MBID, "case: %v", tCase)
+				assert.Falsef(t, got.OnTour, "case: %v", tCase)
+				assert.Equalf(t, 554561574, got.Stats.PlayCount, "case: %v", tCase)
+				assert.Lenf(t, got.Similar, 5, "case: %v", tCase)
+				assert.Equalf(t, "Keane", got.Similar[0].Name, "case: %v", tCase)
+				assert.Equalf(t, []ArtistTag{
+					{Name: "rock", URL: "https://www.last.fm/tag/rock"},
+					{Name: "alternative", URL: "https://www.last.fm/tag/alternative"},
+					{Name: "britpop", URL: "https://www.last.fm/tag/britpop"},
+					{Name: "alternative rock", URL: "https://www.last.fm/tag/alternative+rock"},
+					{Name: "indie", URL: "https://www.last.fm/tag/indie"},
+				}, got.Tags, "case: %v", tCase)
+				assert.Equalf(t, "02 Feb 2006, 02:58", got.Bio.Published, "case: %v", tCase)
+				assert.Containsf(t, got.Bio.Content, "\n\nStudio albums\nParachutes (2000)", "case: %v", tCase)
+			}
+		})
+	}
+}
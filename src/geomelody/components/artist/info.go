@@ -0,0 +1,81 @@
+package artist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"geomelody/components"
+	"geomelody/constants"
+	"geomelody/utils"
+)
+
+type ArtistInfo struct {
+	Name    string                   `json:"name"`
+	URL     string                   `json:"url"`
+	Images  []components.LastFMImage `json:"images"`
+	Summary string                   `json:"summary"`
+	Stats   struct {
+		Listeners int `json:"listeners"`
+		PlayCount int `json:"play_count"`
+	}
+}
+
+// FetchArtistInfo is used to fetch the details of the given artist from LAST API.
+// It returns API response and error.
+func FetchArtistInfo(reqCtx context.Context, artist string) (*LastFMArtistPayload, error) {
+	return fetchArtist(reqCtx, "artist", artist)
+}
+
+// fetchArtist is used to fetch the details of an artist from LAST API, looked up either by "artist" name or by "mbid".
+// It returns API response and error.
+func fetchArtist(reqCtx context.Context, key, value string) (*LastFMArtistPayload, error) {
+	url := fmt.Sprintf("%v", constants.LAST_API_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"method":  "artist.getinfo",
+		key:       value,
+		"api_key": constants.LAST_API_KEY,
+		"format":  "json",
+		"limit":   "1",
+	}
+	data := new(LastFMArtistPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetArtistInfo", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched artist data")
+
+	return data, nil
+}
+
+// ProcessArtistInfo is used to fill images, stats and summary of the artist from the artist.getinfo API data.
+// It returns error.
+func ProcessArtistInfo(data *LastFMArtistPayload, ai *ArtistInfo) error {
+	ar := data.Artist
+	if ar == nil {
+		log.Printf("received empty artist data")
+
+		return nil
+	}
+
+	ai.Images = ar.Image
+
+	if ar.Stats == nil {
+		return errors.New("error while processing artist vendor API data")
+	}
+	ai.Stats.PlayCount = int(ar.Stats.PlayCount)
+	ai.Stats.Listeners = int(ar.Stats.Listeners)
+
+	if ar.Bio == nil {
+		return errors.New("error while processing artist vendor API data")
+	}
+	ai.Summary = strings.Replace(ar.Bio.Summary, "\n", ". ", -1)
+
+	log.Printf("processed artist data")
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package artist
+
+import (
+	"geomelody/components"
+	"geomelody/utils"
+)
+
+type LastFMArtist struct {
+	Name      string                   `json:"name"`
+	MBID      string                   `json:"mbid"`
+	URL       string                   `json:"url"`
+	Listeners utils.FlexInt            `json:"listeners"`
+	Image     []components.LastFMImage `json:"image"`
+	OnTour    utils.FlexInt            `json:"ontour"`
+
+	Stats *struct {
+		Listeners utils.FlexInt `json:"listeners"`
+		PlayCount utils.FlexInt `json:"playcount"`
+	} `json:"stats"`
+
+	Similar struct {
+		Artist []LastFMArtist `json:"artist"`
+	} `json:"similar"`
+
+	Tags struct {
+		Tag []ArtistTag `json:"tag"`
+	} `json:"tags"`
+
+	Bio *struct {
+		Published string `json:"published"`
+		Summary   string `json:"summary"`
+		Content   string `json:"content"`
+	} `json:"bio"`
+}
+
+// LastFMArtistPayload is the artist.getinfo API data.
+type LastFMArtistPayload struct {
+	Artist *LastFMArtist `json:"artist"`
+}
+
+// LastFMTopArtistsPayload is the geo.gettopartists API data.
+type LastFMTopArtistsPayload struct {
+	TopArtists *struct {
+		Artist []LastFMArtist            `json:"artist"`
+		Attr   components.LastFMPageAttr `json:"@attr"`
+	} `json:"topartists"`
+}
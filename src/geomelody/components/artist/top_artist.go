@@ -0,0 +1,260 @@
+package artist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"geomelody/components"
+	"geomelody/constants"
+	"geomelody/utils"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	defaultTopArtistsLimit = 5
+	maxTopArtistsLimit     = 50
+	defaultTopArtistsPage  = 1
+
+	// topArtistsInfoWorkers bounds the artist details fetched concurrently.
+	topArtistsInfoWorkers = 8
+)
+
+type TopArtistComponent struct {
+	components.BaseComponent
+}
+
+type TopArtist interface {
+	GetRegionalTopArtists(*RegionalTopArtistsForm) (*RegionalTopArtistsResponse, error)
+	GetRegionalTopArtistsForm() *RegionalTopArtistsForm
+	GetComponentAppError() *utils.AppError
+	SetComponentAppError(int, error)
+}
+
+type RegionalTopArtistsForm struct {
+	Country  string `json:"country"`
+	Limit    int    `json:"limit"`
+	Page     int    `json:"page"`
+	UseCache bool   `json:"use_cache"`
+}
+
+type RankedArtist struct {
+	Rank      int `json:"rank"`
+	Listeners int `json:"listeners"`
+
+	ArtistsInfo ArtistInfo `json:"artists_info"`
+
+	// Status and Error report the outcome of fetching the details of the artist.
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type RegionalTopArtistsResponse struct {
+	Meta struct {
+		Country    string `json:"country"`
+		Page       int    `json:"page"`
+		PerPage    int    `json:"perPage"`
+		TotalPages int    `json:"totalPages"`
+		Total      int    `json:"total"`
+	} `json:"meta"`
+
+	Artists []RankedArtist `json:"artists"`
+}
+
+// GetRegionalTopArtists is used to call required external APIs to fetch the top artists of the given country along with the details of each artist.
+// It returns top artists data and error.
+func (tac *TopArtistComponent) GetRegionalTopArtists(form *RegionalTopArtistsForm) (*RegionalTopArtistsResponse, error) {
+	resp := new(RegionalTopArtistsResponse)
+	var err error
+	var data *LastFMTopArtistsPayload
+	if err = form.Valid(); err != nil {
+		tac.SetComponentAppError(http.StatusBadRequest, err)
+
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("artists:%v:%v:%v", form.Country, form.Limit, form.Page)
+	if components.IsRespInCache(form.UseCache, cacheKey, tac.Cache, resp) {
+		return resp, nil
+	}
+
+	if data, err = fetchRegionalTopArtistsData(tac.ReqCtx, form.Country, strconv.Itoa(form.Limit), strconv.Itoa(form.Page)); err != nil {
+		tac.SetComponentAppError(utils.StatusForError(err), err)
+	} else if err = processRegionalTopArtistsData(data, resp); err != nil {
+		tac.SetComponentAppError(utils.StatusForError(err), err)
+	} else {
+		enriched := fetchTopArtistsInfo(tac.ReqCtx, resp)
+		components.CheckAndCacheResp(form.UseCache && enriched, cacheKey, tac.Cache, resp)
+	}
+
+	return resp, err
+}
+
+// fetchRegionalTopArtistsData is used to fetch a page of top artists of the given country from LAST API.
+// It returns API response and error.
+func fetchRegionalTopArtistsData(reqCtx context.Context, country, limit, page string) (*LastFMTopArtistsPayload, error) {
+	url := fmt.Sprintf("%v", constants.LAST_API_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"method":  "geo.gettopartists",
+		"country": country,
+		"api_key": constants.LAST_API_KEY,
+		"format":  "json",
+		"limit":   limit,
+		"page":    page,
+	}
+	data := new(LastFMTopArtistsPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTopArtistsByCountry", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched regional artists data")
+
+	return data, nil
+}
+
+func processRegionalTopArtistsData(data *LastFMTopArtistsPayload, rtar *RegionalTopArtistsResponse) error {
+	topArtists := data.TopArtists
+	if topArtists == nil {
+		return errors.New("received empty artists data from vendor API. Please check input params")
+	}
+
+	rtar.Meta.Country = topArtists.Attr.Country
+	rtar.Meta.Page = int(topArtists.Attr.Page)
+	rtar.Meta.PerPage = int(topArtists.Attr.PerPage)
+	rtar.Meta.TotalPages = int(topArtists.Attr.TotalPages)
+	rtar.Meta.Total = int(topArtists.Attr.Total)
+
+	rtar.Artists = make([]RankedArtist, 0, len(topArtists.Artist))
+	for i, ar := range topArtists.Artist {
+		rankedArtist := new(RankedArtist)
+
+		rankedArtist.Rank = (rtar.Meta.Page-1)*rtar.Meta.PerPage + i + 1
+		rankedArtist.Listeners = int(ar.Listeners)
+		rankedArtist.ArtistsInfo.Name = ar.Name
+		rankedArtist.ArtistsInfo.URL = ar.URL
+		rankedArtist.ArtistsInfo.Images = ar.Image
+
+		rtar.Artists = append(rtar.Artists, *rankedArtist)
+	}
+
+	log.Printf("processed regional artists data")
+
+	return nil
+}
+
+// fetchTopArtistsInfo is used to enrich the ranked artists with the artist details from LAST API concurrently using a bounded pool of workers.
+// Failures are reported per artist, so that one failed lookup does not fail the whole response.
+// It returns true if the details of every artist were fetched.
+func fetchTopArtistsInfo(reqCtx context.Context, rtar *RegionalTopArtistsResponse) bool {
+	jobs := make(chan int)
+	wg := new(sync.WaitGroup)
+	for w := 0; w < min(topArtistsInfoWorkers, len(rtar.Artists)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fetchRankedArtistInfo(reqCtx, &rtar.Artists[i])
+			}
+		}()
+	}
+
+	for i := range rtar.Artists {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, ra := range rtar.Artists {
+		if ra.Error != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fetchRankedArtistInfo is used to fetch the details of the given ranked artist, storing the outcome in its status.
+func fetchRankedArtistInfo(reqCtx context.Context, ra *RankedArtist) {
+	data, err := FetchArtistInfo(reqCtx, ra.ArtistsInfo.Name)
+	if err == nil {
+		err = ProcessArtistInfo(data, &ra.ArtistsInfo)
+	}
+
+	if err != nil {
+		log.Printf("error fetching the details of %v: %v", ra.ArtistsInfo.Name, err)
+		ra.Status = utils.StatusForError(err)
+		ra.Error = err.Error()
+	} else {
+		ra.Status = http.StatusOK
+	}
+}
+
+// GetRegionalTopArtistsForm is used to create a new regional top artists form instance.
+// It returns regional top artists form instance.
+func (tac *TopArtistComponent) GetRegionalTopArtistsForm() *RegionalTopArtistsForm {
+	return new(RegionalTopArtistsForm)
+}
+
+// GetComponentAppError is used to retrieve app error from the component struct.
+// It returns app error of the component.
+func (tac *TopArtistComponent) GetComponentAppError() *utils.AppError {
+	return tac.AppError
+}
+
+func (tac *TopArtistComponent) SetComponentAppError(status int, err error) {
+	tac.AppError = &utils.AppError{
+		Status: status,
+		Error:  err,
+	}
+}
+
+// Valid validates and sanitizes the top regional artists form.
+func (f *RegionalTopArtistsForm) Valid() error {
+	country, errMsg, err := components.LookupCountry(f.Country)
+	if err != nil {
+		return err
+	} else if errMsg == "" {
+		f.Country = country
+	}
+
+	if f.Limit == 0 {
+		f.Limit = defaultTopArtistsLimit
+	} else if f.Limit < 0 || f.Limit > maxTopArtistsLimit {
+		if errMsg != "" {
+			errMsg += "\n"
+		}
+		errMsg += fmt.Sprintf("`limit` parameter is invalid, it should be between 1 and %v", maxTopArtistsLimit)
+	}
+
+	if f.Page == 0 {
+		f.Page = defaultTopArtistsPage
+	} else if f.Page < 0 {
+		if errMsg != "" {
+			errMsg += "\n"
+		}
+		errMsg += "`page` parameter is invalid"
+	}
+
+	p := bluemonday.UGCPolicy()
+	f.Country = p.Sanitize(f.Country)
+
+	if errMsg != "" {
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
+func init() {
+	components.ComponentMap["TopArtist"] = func(bc *components.BaseComponent) interface{} {
+		c := &TopArtistComponent{BaseComponent: *bc}
+
+		return TopArtist(c)
+	}
+}
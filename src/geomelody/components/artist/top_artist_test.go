@@ -0,0 +1,222 @@
+package artist
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"geomelody/components"
+	"geomelody/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionalTopArtistsForm_Valid(t *testing.T) {
+	constants.COUNTRIES_JSON_FILE_NAME = "../../countries.json"
+
+	type vars struct {
+		form *RegionalTopArtistsForm
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   *RegionalTopArtistsForm
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should fail when country is empty",
+			vars: vars{
+				form: &RegionalTopArtistsForm{
+					Country: "",
+				},
+			},
+			hasErr: true,
+			err:    "`country` parameter is invalid",
+		},
+		{
+			name: "should fail when country format is not ISO 3166-1-Alpha-2 code format",
+			vars: vars{
+				form: &RegionalTopArtistsForm{
+					Country: "india",
+				},
+			},
+			hasErr: true,
+			err:    "`country` not found in our database",
+		},
+		{
+			name: "should fail when limit is out of range",
+			vars: vars{
+				form: &RegionalTopArtistsForm{
+					Country: "in",
+					Limit:   -1,
+				},
+			},
+			hasErr: true,
+			err:    "`limit` parameter is invalid",
+		},
+		{
+			name: "should success and apply default limit and page",
+			vars: vars{
+				form: &RegionalTopArtistsForm{
+					Country: "in",
+				},
+			},
+			want: &RegionalTopArtistsForm{
+				Country: "india",
+				Limit:   defaultTopArtistsLimit,
+				Page:    defaultTopArtistsPage,
+			},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+
+			// Run test
+			err := form.Valid()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Exactlyf(t, tCase.want, form, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestTopArtistComponent_GetRegionalTopArtists(t *testing.T) {
+	constants.COUNTRIES_JSON_FILE_NAME = "../../countries.json"
+
+	type vars struct {
+		component components.BaseComponent
+
+		form *RegionalTopArtistsForm
+
+		headers map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want         string
+		artistStatus int
+		artistErr    string
+		hasErr       bool
+		err          string
+	}{
+		{
+			name: "should success to fetch the top artists of the region",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopArtistsForm{
+					Country: "in",
+					Limit:   2,
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			want: `{"meta":{"country":"India","page":1,"perPage":2,"totalPages":1245,"total":2490},"artists":[{"rank":1,"listeners":7296979,"artists_info":{"name":"Coldplay","url":"https://www.last.fm/music/Coldplay","images":[{"#text":"https://lastfm.freetls.fastly.net/i/u/34s/2a96cbd8b46e442fc41c2b86b821562f.png","size":"small"},{"#text":"https://lastfm.freetls.fastly.net/i/u/64s/2a96cbd8b46e442fc41c2b86b821562f.png","size":"medium"},{"#text":"https://lastfm.freetls.fastly.net/i/u/174s/2a96cbd8b46e442fc41c2b86b821562f.png","size":"large"},{"#text":"https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png","size":"extralarge"},{"#text":"https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png","size":"mega"},{"#text":"https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png","size":""}],"summary":"Coldplay is a British alternative rock and britpop band formed in London in 1997. They consist of vocalist and pianist Chris Martin, guitarist Jonny Buckland, bassist Guy Berryman, drummer Will Champion and creative director Phil Harvey. They met at University College London and began playing music together from 1996 to 1998, initially calling themselves Starfish. Coldplay's music incorporates elements of soft rock, pop rock, piano rock, and post-britpop. \u003ca href=\"https://www.last.fm/music/Coldplay\"\u003eRead more on Last.fm\u003c/a\u003e","Stats":{"listeners":7296979,"play_count":554561574}},"status":200},{"rank":2,"listeners":1201455,"artists_info":{"name":"Arijit Singh","url":"https://www.last.fm/music/Arijit+Singh","images":[{"#text":"https://lastfm.freetls.fastly.net/i/u/34s/2a96cbd8b46e442fc41c2b86b821562f.png","size":"small"},{"#text":"https://lastfm.freetls.fastly.net/i/u/64s/2a96cbd8b46e442fc41c2b86b821562f.png","size":"medium"},{"#text":"https://lastfm.freetls.fastly.net/i/u/174s/2a96cbd8b46e442fc41c2b86b821562f.png","size":"large"},{"#text":"https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png","size":"extralarge"},{"#text":"https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png","size":"mega"},{"#text":"https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png","size":""}],"summary":"Coldplay is a British alternative rock and britpop band formed in London in 1997. They consist of vocalist and pianist Chris Martin, guitarist Jonny Buckland, bassist Guy Berryman, drummer Will Champion and creative director Phil Harvey. They met at University College London and began playing music together from 1996 to 1998, initially calling themselves Starfish. Coldplay's music incorporates elements of soft rock, pop rock, piano rock, and post-britpop. \u003ca href=\"https://www.last.fm/music/Coldplay\"\u003eRead more on Last.fm\u003c/a\u003e","Stats":{"listeners":7296979,"play_count":554561574}},"status":200}]}`,
+		},
+		{
+			name: "should report the artist details that could not be fetched on each artist",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopArtistsForm{
+					Country: "in",
+				},
+				headers: map[string]string{
+					"x-mock-api":               "default",
+					"x-mock-api-getartistinfo": "not_found",
+				},
+			},
+			artistStatus: http.StatusNotFound,
+			artistErr:    "GetArtistInfo vendor API error, not found",
+		},
+		{
+			name: "should report the artist details payload that does not match the schema on each artist",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopArtistsForm{
+					Country: "in",
+				},
+				headers: map[string]string{
+					"x-mock-api":               "default",
+					"x-mock-api-getartistinfo": "invalid_schema",
+				},
+			},
+			artistStatus: http.StatusBadGateway,
+			artistErr:    "error while decoding GetArtistInfo vendor API data",
+		},
+		{
+			name: "should fail when the top artists could not be fetched",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopArtistsForm{
+					Country: "in",
+				},
+				headers: map[string]string{
+					"x-mock-api": "error_response",
+				},
+			},
+			hasErr: true,
+			err:    "error",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+			tac := &TopArtistComponent{
+				BaseComponent: tCase.vars.component,
+			}
+			ctx := tac.ReqCtx
+			ctx = context.WithValue(ctx, "x-mock-headers", tCase.vars.headers)
+			tac.ReqCtx = ctx
+
+			// Run test
+			got, err := tac.GetRegionalTopArtists(form)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else if tCase.artistErr != "" {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.NotEmptyf(t, got.Artists, "case: %v", tCase)
+				for _, ra := range got.Artists {
+					assert.Equalf(t, tCase.artistStatus, ra.Status, "case: %v", tCase)
+					assert.Containsf(t, ra.Error, tCase.artistErr, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				tempWant := new(RegionalTopArtistsResponse)
+				_ = json.Unmarshal([]byte(tCase.want), tempWant)
+				assert.Equal(t, tempWant, got, "case: %v", tCase)
+			}
+		})
+	}
+}
@@ -4,14 +4,12 @@ import (
 	"context"
 
 	"geomelody/utils"
-
-	"github.com/gomodule/redigo/redis"
 )
 
 type BaseComponent struct {
-	ReqCtx    context.Context
-	AppError  *utils.AppError
-	RedisConn redis.Conn
+	ReqCtx   context.Context
+	AppError *utils.AppError
+	Cache    utils.Cache
 }
 
 var ComponentMap = make(map[string]func(*BaseComponent) interface{})
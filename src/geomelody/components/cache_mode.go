@@ -0,0 +1,71 @@
+package components
+
+import (
+	"errors"
+	"strings"
+)
+
+// CacheMode tells how a request uses the cached responses.
+type CacheMode string
+
+const (
+	// CacheDefault reads the cached response and stores the fetched one.
+	CacheDefault CacheMode = "default"
+	// CacheBypass neither reads nor stores the cached response.
+	CacheBypass CacheMode = "bypass"
+	// CacheRefresh skips the cached response but overwrites it with the fetched one.
+	CacheRefresh CacheMode = "refresh"
+	// CacheOnlyIfCached only serves the cached response and never calls the vendors.
+	CacheOnlyIfCached CacheMode = "only-if-cached"
+)
+
+// ErrNotCached is returned in only-if-cached mode when the response is not in cache.
+var ErrNotCached = errors.New("response not found in cache, and `cache` is only-if-cached")
+
+// Reads reports whether the cached response may be served.
+func (m CacheMode) Reads() bool {
+	return m == CacheDefault || m == CacheOnlyIfCached
+}
+
+// Writes reports whether the fetched response must be stored in cache.
+func (m CacheMode) Writes() bool {
+	return m == CacheDefault || m == CacheRefresh
+}
+
+// ResolveCacheMode validates the given cache mode, falling back to the legacy use_cache flag when it is unset.
+// It returns the cache mode and error if the mode is unknown.
+func ResolveCacheMode(mode CacheMode, useCache bool) (CacheMode, error) {
+	switch CacheMode(strings.ToLower(strings.TrimSpace(string(mode)))) {
+	case "":
+		if useCache {
+			return CacheDefault, nil
+		}
+		return CacheBypass, nil
+	case CacheDefault:
+		return CacheDefault, nil
+	case CacheBypass:
+		return CacheBypass, nil
+	case CacheRefresh:
+		return CacheRefresh, nil
+	case CacheOnlyIfCached:
+		return CacheOnlyIfCached, nil
+	}
+
+	return mode, errors.New("`cache` parameter is invalid, it should be one of default, bypass, refresh or only-if-cached")
+}
+
+// CacheModeFromHeader maps the no-cache and only-if-cached directives of a Cache-Control request header onto a cache mode.
+// It returns the cache mode, empty if the header has none of them.
+func CacheModeFromHeader(cacheControl string) CacheMode {
+	var mode CacheMode
+	for _, directive := range strings.Split(cacheControl, ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "only-if-cached":
+			return CacheOnlyIfCached
+		case "no-cache":
+			mode = CacheRefresh
+		}
+	}
+
+	return mode
+}
@@ -0,0 +1,115 @@
+package components
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"geomelody/constants"
+	"geomelody/utils"
+)
+
+// IsRespInCache looks up the given key in cache and decodes the cached data into resp.
+// A nil cache is treated as an empty one.
+// It returns true if the response was served from cache.
+func IsRespInCache(useCache bool, key string, cache utils.Cache, resp interface{}) bool {
+	if useCache && cache != nil {
+		dataStr, err := cache.Get(key)
+		if err != nil {
+			log.Printf("data not found in cache")
+		} else if dataBytes, err := base64.StdEncoding.DecodeString(dataStr); err != nil {
+			log.Printf("error while decoding base64 cache data")
+		} else if err := json.Unmarshal(dataBytes, resp); err != nil {
+			log.Printf("error unmarshaling cache data")
+		} else {
+			log.Printf("data found in cache")
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckAndCacheResp stores the given response in cache under the given key.
+func CheckAndCacheResp(useCache bool, key string, cache utils.Cache, resp interface{}) {
+	if useCache && cache != nil {
+		if respBytes, err := json.Marshal(resp); err != nil {
+			log.Printf("error marshaling data to store in cache")
+		} else if respStr := base64.StdEncoding.EncodeToString(respBytes); respStr != "" {
+			ttl, _ := strconv.Atoi(constants.REDIS_DEFAULT_EXPIRY)
+			if err := cache.Set(key, respStr, time.Duration(ttl)*time.Second); err != nil {
+				log.Printf("error setting data in cache")
+			} else {
+				log.Printf("data succesfully stored in cache")
+			}
+		}
+	}
+}
+
+// timedResp is the cached form of the responses stored along with their time.
+type timedResp struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// GetTimedRespFromCache looks up the given key in cache and decodes the response stored by CacheTimedResp into resp.
+// A nil cache is treated as an empty one.
+// It returns the time the response was stored and true if it was found.
+func GetTimedRespFromCache(useCache bool, key string, cache utils.Cache, resp interface{}) (time.Time, bool) {
+	entry := new(timedResp)
+	if !IsRespInCache(useCache, key, cache, entry) {
+		return time.Time{}, false
+	} else if len(entry.Data) == 0 || entry.StoredAt.IsZero() {
+		log.Printf("cache data has no timestamp")
+		return time.Time{}, false
+	} else if err := json.Unmarshal(entry.Data, resp); err != nil {
+		log.Printf("error unmarshaling cache data")
+		return time.Time{}, false
+	}
+
+	return entry.StoredAt, true
+}
+
+// CacheTimedResp stores the given response along with the current time in cache under the given key, for ttl.
+func CacheTimedResp(useCache bool, key string, cache utils.Cache, resp interface{}, ttl time.Duration) {
+	if useCache && cache != nil {
+		if respBytes, err := json.Marshal(resp); err != nil {
+			log.Printf("error marshaling data to store in cache")
+		} else if entryBytes, err := json.Marshal(timedResp{StoredAt: time.Now(), Data: respBytes}); err != nil {
+			log.Printf("error marshaling data to store in cache")
+		} else if err := cache.Set(key, base64.StdEncoding.EncodeToString(entryBytes), ttl); err != nil {
+			log.Printf("error setting data in cache")
+		} else {
+			log.Printf("data succesfully stored in cache")
+		}
+	}
+}
+
+// LookupCountry resolves the given ISO 3166-1-Alpha-2 code against the countries database.
+// It returns the country name, validation error message and error.
+func LookupCountry(code string) (string, string, error) {
+	if code == "" {
+		return "", "`country` parameter is invalid", nil
+	}
+
+	countriesStr, err := os.ReadFile(constants.COUNTRIES_JSON_FILE_NAME)
+	if err != nil {
+		return "", "", err
+	}
+
+	var countriesMap map[string]string
+	err = json.Unmarshal(countriesStr, &countriesMap)
+	if err != nil {
+		return "", "", err
+	}
+
+	if country, ok := countriesMap[strings.ToLower(code)]; ok {
+		return country, "", nil
+	}
+
+	return "", "`country` not found in our database. Please check the country input param, it should follow the ISO 3166-1-Alpha-2 code format", nil
+}
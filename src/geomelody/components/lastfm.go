@@ -0,0 +1,20 @@
+package components
+
+import (
+	"geomelody/utils"
+)
+
+type LastFMImage struct {
+	URL  string `json:"#text"`
+	Size string `json:"size"`
+}
+
+// LastFMPageAttr holds the paging details which LAST API sends under the "@attr" key of the listing payloads.
+type LastFMPageAttr struct {
+	Country    string        `json:"country"`
+	Tag        string        `json:"tag"`
+	Page       utils.FlexInt `json:"page"`
+	PerPage    utils.FlexInt `json:"perPage"`
+	TotalPages utils.FlexInt `json:"totalPages"`
+	Total      utils.FlexInt `json:"total"`
+}
@@ -0,0 +1,160 @@
+package track
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"geomelody/components"
+	"geomelody/components/artist"
+	"geomelody/utils"
+)
+
+type LastFMTrack struct {
+	Name      string         `json:"name"`
+	Duration  utils.FlexInt  `json:"duration"`
+	Listeners *utils.FlexInt `json:"listeners"`
+	PlayCount utils.FlexInt  `json:"playcount"`
+	Match     float64        `json:"match"`
+	URL       string         `json:"url"`
+
+	Artist struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"artist"`
+
+	Attr struct {
+		Rank utils.FlexInt `json:"rank"`
+	} `json:"@attr"`
+}
+
+// LastFMTracksPayload is the geo.gettoptracks and chart.gettoptracks API data.
+type LastFMTracksPayload struct {
+	Tracks *struct {
+		Track []LastFMTrack             `json:"track"`
+		Attr  components.LastFMPageAttr `json:"@attr"`
+	} `json:"tracks"`
+}
+
+// Validate checks that every track has the fields the top tracks are built from.
+// It returns error naming the missing fields of the first incomplete track.
+func (p *LastFMTracksPayload) Validate() error {
+	return p.validateTracks(true)
+}
+
+// LastFMTagTracksPayload is the tag.gettoptracks API data, whose tracks have no listeners.
+type LastFMTagTracksPayload struct {
+	LastFMTracksPayload
+}
+
+// Validate checks that every track has the fields the top tracks are built from, except the listeners.
+// It returns error naming the missing fields of the first incomplete track.
+func (p *LastFMTagTracksPayload) Validate() error {
+	return p.validateTracks(false)
+}
+
+func (p *LastFMTracksPayload) validateTracks(requireListeners bool) error {
+	if p.Tracks == nil {
+		return nil
+	}
+
+	for i, track := range p.Tracks.Track {
+		var missing []string
+		if track.Name == "" {
+			missing = append(missing, "name")
+		}
+		if track.URL == "" {
+			missing = append(missing, "url")
+		}
+		if requireListeners && track.Listeners == nil {
+			missing = append(missing, "listeners")
+		}
+		if track.Artist.Name == "" {
+			missing = append(missing, "artist")
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("track %d is missing the required fields: %v", i, strings.Join(missing, ", "))
+		}
+	}
+
+	return nil
+}
+
+// LastFMSimilarTracksPayload is the track.getsimilar API data.
+type LastFMSimilarTracksPayload struct {
+	SimilarTracks *struct {
+		Track []LastFMTrack `json:"track"`
+	} `json:"similartracks"`
+}
+
+// LastFMTagPayload is the tag.getinfo API data.
+type LastFMTagPayload struct {
+	Tag *struct {
+		Name  string        `json:"name"`
+		Total utils.FlexInt `json:"total"`
+		Reach utils.FlexInt `json:"reach"`
+
+		Wiki struct {
+			Summary string `json:"summary"`
+			Content string `json:"content"`
+		} `json:"wiki"`
+	} `json:"tag"`
+}
+
+// LastFMProvider is the chart, artist and similarity provider backed by LAST API.
+type LastFMProvider struct{}
+
+// RegionalTopTrack is used to fetch the top track of the given country.
+// It returns error.
+func (LastFMProvider) RegionalTopTrack(ctx context.Context, country string, rttr *RegionalTopTrackResponse) error {
+	data, err := fetchRegionalTopTrackData(ctx, country, "1", "1")
+	if err != nil {
+		return err
+	}
+
+	return processRegionalTrackData(data, rttr)
+}
+
+// GlobalTopTrack is used to fetch the worldwide top track.
+// It returns error.
+func (LastFMProvider) GlobalTopTrack(ctx context.Context, rttr *RegionalTopTrackResponse) error {
+	data, err := fetchGlobalTopTrackData(ctx, "1", "1")
+	if err != nil {
+		return err
+	}
+
+	return processRegionalTrackData(data, rttr)
+}
+
+// TagTopTrack is used to fetch the top track of the given tag.
+// It returns error.
+func (LastFMProvider) TagTopTrack(ctx context.Context, tag string, rttr *RegionalTopTrackResponse) error {
+	data, err := fetchTagTopTrackData(ctx, tag, "1", "1")
+	if err != nil {
+		return err
+	}
+
+	return processTagTrackData(data, rttr)
+}
+
+// ArtistInfo is used to fetch the details of the given artist.
+// It returns error.
+func (LastFMProvider) ArtistInfo(ctx context.Context, artistName string, ai *artist.ArtistInfo) error {
+	data, err := artist.FetchArtistInfo(ctx, artistName)
+	if err != nil {
+		return err
+	}
+
+	return artist.ProcessArtistInfo(data, ai)
+}
+
+// SimilarTracks is used to fetch the suggestions based on the given track and artist.
+// It returns track suggestions and error.
+func (LastFMProvider) SimilarTracks(ctx context.Context, trackName, artistName string) ([]TrackSuggestion, error) {
+	data, err := fetchTrackSuggestions(ctx, trackName, artistName)
+	if err != nil {
+		return nil, err
+	}
+
+	return processTrackSuggestionsData(data)
+}
@@ -0,0 +1,443 @@
+package track
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"geomelody/components"
+	"geomelody/constants"
+	"geomelody/utils"
+)
+
+// defaultLang is the language the names of a track are translated in when no language is asked for.
+const defaultLang = "en"
+
+var (
+	langRegex            = regexp.MustCompile(`^[a-z]{2}$`)
+	disclaimerRegex      = regexp.MustCompile(`\*{3,}\s*(.*?)\s*\*{3,}(?:\s*\(\d+\))?`)
+	stanzaSeparatorRegex = regexp.MustCompile(`\n[ \t]*\n\s*`)
+)
+
+type LyricsComponent struct {
+	components.BaseComponent
+}
+
+type Lyrics interface {
+	GetLyrics(*LyricsForm) (*LyricsResponse, error)
+	GetLyricsForm() *LyricsForm
+	GetSyncedLyrics(*SyncedLyricsForm) (*SyncedLyricsResponse, error)
+	GetSyncedLyricsForm() *SyncedLyricsForm
+	GetComponentAppError() *utils.AppError
+	SetComponentAppError(int, error)
+}
+
+type LyricsForm struct {
+	Track         string `json:"track"`
+	Artist        string `json:"artist"`
+	TrackID       int    `json:"track_id"`
+	CommonTrackID int    `json:"commontrack_id"`
+	Lang          string `json:"lang"`
+	FlatLyrics    bool   `json:"flat_lyrics"`
+	UseCache      bool   `json:"use_cache"`
+}
+
+type TrackNameTranslation struct {
+	Language    string `json:"language"`
+	Translation string `json:"translation"`
+}
+
+type LyricsTrack struct {
+	TrackID       int    `json:"track_id"`
+	CommonTrackID int    `json:"commontrack_id"`
+	Name          string `json:"name"`
+	ArtistName    string `json:"artist_name"`
+	AlbumName     string `json:"album_name"`
+}
+
+// LyricsText holds the lyrics split into stanzas of lines, along with the vendor disclaimer and copyright.
+// The dot joined Lyrics string is only kept for the clients asking for flat lyrics.
+type LyricsText struct {
+	Lyrics     string     `json:"lyrics,omitempty"`
+	Stanzas    [][]string `json:"stanzas,omitempty"`
+	Disclaimer string     `json:"lyrics_disclaimer,omitempty"`
+	Copyright  string     `json:"lyrics_copyright,omitempty"`
+}
+
+// LyricsTranslation holds the names of a track and its artist, and its lyrics, in the asked language.
+// The original values are kept for the ones that have no translation, LyricsTranslated telling whether the lyrics have one.
+type LyricsTranslation struct {
+	Language         string     `json:"language,omitempty"`
+	TrackName        string     `json:"track_name,omitempty"`
+	ArtistName       string     `json:"artist_name,omitempty"`
+	LyricsTranslated bool       `json:"lyrics_translated"`
+	Lyrics           string     `json:"lyrics,omitempty"`
+	Stanzas          [][]string `json:"stanzas,omitempty"`
+}
+
+type LyricsResponse struct {
+	Track LyricsTrack `json:"track"`
+
+	HasLyrics bool   `json:"has_lyrics"`
+	Explicit  bool   `json:"explicit"`
+	Language  string `json:"language"`
+	LyricsText
+
+	Translation struct {
+		HasTranslation bool                   `json:"has_translation"`
+		Translations   []TrackNameTranslation `json:"translations"`
+		LyricsTranslation
+	} `json:"translation"`
+}
+
+type MusicMixSearchResponse struct {
+	HasTranslation bool                   `json:"has_translation"`
+	HasLyrics      bool                   `json:"has_lyrics"`
+	HasSubtitles   bool                   `json:"has_subtitles"`
+	HasRichsync    bool                   `json:"has_richsync"`
+	Explicit       bool                   `json:"explicit"`
+	TrackID        int                    `json:"track_id"`
+	CommonTrackID  int                    `json:"commontrack_id"`
+	TrackName      string                 `json:"track_name"`
+	ArtistID       int                    `json:"artist_id"`
+	ArtistName     string                 `json:"artist_name"`
+	AlbumID        int                    `json:"album_id"`
+	AlbumName      string                 `json:"album_name"`
+	Translations   []TrackNameTranslation `json:"translations"`
+}
+
+type MusicMixLyricsResponse struct {
+	LyricsText
+	Explicit bool   `json:"explicit"`
+	Language string `json:"lyrics_language"`
+}
+
+// GetLyrics is used to fetch the lyrics of the given track and artist, or of the given Musixmatch track ID.
+// It returns lyrics data and error.
+func (lc *LyricsComponent) GetLyrics(form *LyricsForm) (*LyricsResponse, error) {
+	resp := new(LyricsResponse)
+	var err error
+	var data *MusicMixTrackPayload
+	if err = form.Valid(); err != nil {
+		lc.SetComponentAppError(http.StatusBadRequest, err)
+
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("lyrics:%v:%v:%v:%v:%v", form.Lang, form.TrackID, form.CommonTrackID, strings.ToLower(form.Artist), strings.ToLower(form.Track))
+	if components.IsRespInCache(form.UseCache, cacheKey, lc.Cache, resp) {
+		resp.formatLyrics(form.FlatLyrics)
+
+		return resp, nil
+	}
+
+	musicMixResp := new(MusicMixSearchResponse)
+	if form.TrackID != 0 || form.CommonTrackID != 0 {
+		data, err = fetchTrack(lc.ReqCtx, strconv.Itoa(form.TrackID), strconv.Itoa(form.CommonTrackID))
+	} else {
+		data, err = fetchTrackID(lc.ReqCtx, form.Artist, form.Track)
+	}
+
+	if err != nil {
+		lc.SetComponentAppError(utils.StatusForError(err), err)
+	} else if err = processTrackIDData(data, musicMixResp, ""); err != nil {
+		lc.SetComponentAppError(utils.StatusForError(err), err)
+	} else if musicMixResp.TrackID == 0 && musicMixResp.CommonTrackID == 0 {
+		err = errors.New("track not found. Please check the input params")
+		lc.SetComponentAppError(http.StatusNotFound, err)
+	} else if err = lc.fetchLyricsBody(musicMixResp, resp, form.Lang); err != nil {
+		lc.SetComponentAppError(utils.StatusForError(err), err)
+	} else {
+		components.CheckAndCacheResp(form.UseCache, cacheKey, lc.Cache, resp)
+		resp.formatLyrics(form.FlatLyrics)
+	}
+
+	return resp, err
+}
+
+// formatLyrics is used to drop the flat lyrics strings from the response unless the client asked for them.
+// The cached response always keeps them, so it is called only after caching.
+func (lr *LyricsResponse) formatLyrics(flat bool) {
+	if !flat {
+		lr.Lyrics = ""
+		lr.Translation.Lyrics = ""
+	}
+}
+
+// fetchLyricsBody is used to fill the lyrics response from the matched track and fetch the lyrics body if the track has lyrics,
+// along with the translation in the given language if asked for. A missing translation never fails the lyrics.
+// It returns error.
+func (lc *LyricsComponent) fetchLyricsBody(mms *MusicMixSearchResponse, lr *LyricsResponse, lang string) error {
+	lr.Track = mms.lyricsTrack()
+	lr.HasLyrics = mms.HasLyrics
+	lr.Explicit = mms.Explicit
+	lr.Translation.HasTranslation = len(mms.Translations) > 0
+	lr.Translation.Translations = mms.Translations
+
+	if mms.HasLyrics {
+		musicMixLyrics := new(MusicMixLyricsResponse)
+		if data, err := fetchLyrics(lc.ReqCtx, strconv.Itoa(mms.TrackID), strconv.Itoa(mms.CommonTrackID)); err != nil {
+			return err
+		} else if err = processLyricsData(data, musicMixLyrics); err != nil {
+			return err
+		}
+
+		lr.LyricsText = musicMixLyrics.LyricsText
+		lr.Language = musicMixLyrics.Language
+		lr.Explicit = lr.Explicit || musicMixLyrics.Explicit
+	}
+
+	if lang != "" {
+		lr.Translation.LyricsTranslation = fetchTranslation(lc.ReqCtx, mms, lr.LyricsText, lr.Language, lang)
+	}
+
+	return nil
+}
+
+func fetchTrackID(reqCtx context.Context, artist, track string) (*MusicMixTrackPayload, error) {
+	url := fmt.Sprintf("%vtrack.search", constants.MUSIC_MIX_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"q_track":   track,
+		"q_artist":  artist,
+		"apikey":    constants.MUSIC_MIX_API_KEY,
+		"page_size": "1",
+	}
+	data := new(MusicMixTrackPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTrackID", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched track ID data")
+
+	return data, nil
+}
+
+// fetchTrack is used to fetch the track details of the given Musixmatch track ID or common track ID from MUSIC MIX API.
+// It returns API response and error.
+func fetchTrack(reqCtx context.Context, trackID, commonTrackID string) (*MusicMixTrackPayload, error) {
+	url := fmt.Sprintf("%vtrack.get", constants.MUSIC_MIX_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"apikey": constants.MUSIC_MIX_API_KEY,
+	}
+	if trackID != "0" {
+		params["track_id"] = trackID
+	}
+	if commonTrackID != "0" {
+		params["commontrack_id"] = commonTrackID
+	}
+	data := new(MusicMixTrackPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTrack", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched track data")
+
+	return data, nil
+}
+
+// processTrackIDData is used to read the matched track of a track.search or track.get API data.
+// The names of the track are replaced by their translation in the given language if MUSIC MIX API has one,
+// the original names being kept when the language is empty.
+// It returns error.
+func processTrackIDData(data *MusicMixTrackPayload, mms *MusicMixSearchResponse, lang string) error {
+	body := data.Message.Body
+
+	track := body.Track
+	if body.TrackList != nil {
+		if len(body.TrackList) != 1 {
+			log.Printf("received more than one track ID data, hence not processed")
+			return nil
+		}
+		track = &body.TrackList[0].Track
+	}
+
+	if track == nil {
+		return errors.New("error while processing track ID vendor API data")
+	}
+
+	mms.TrackID = track.TrackID
+	mms.CommonTrackID = track.CommonTrackID
+	mms.ArtistID = track.ArtistID
+	mms.AlbumID = track.AlbumID
+	mms.AlbumName = track.AlbumName
+	mms.Explicit = track.Explicit == 1
+
+	checkForTranslation(track, mms, lang)
+	if !mms.HasTranslation {
+		mms.TrackName = track.TrackName
+		mms.ArtistName = track.ArtistName
+	}
+
+	mms.HasLyrics = track.HasLyrics == 1
+	if !mms.HasLyrics {
+		log.Printf("lyrics not present for the track")
+	}
+	mms.HasSubtitles = track.HasSubtitles == 1
+	mms.HasRichsync = track.HasRichsync == 1
+
+	log.Printf("processed track ID data")
+
+	return nil
+}
+
+// lyricsTrack is used to build the track details of the lyrics responses from the matched track.
+// It returns lyrics track details.
+func (mms *MusicMixSearchResponse) lyricsTrack() LyricsTrack {
+	return LyricsTrack{
+		TrackID:       mms.TrackID,
+		CommonTrackID: mms.CommonTrackID,
+		Name:          mms.TrackName,
+		ArtistName:    mms.ArtistName,
+		AlbumName:     mms.AlbumName,
+	}
+}
+
+// checkForTranslation is used to list the translations of the track name, and to use the one in the given language if any.
+func checkForTranslation(track *MusicMixTrack, mms *MusicMixSearchResponse, lang string) {
+	mms.Translations = make([]TrackNameTranslation, 0, len(track.TrackNameTranslationList))
+	for _, translation := range track.TrackNameTranslationList {
+		transVal := translation.TrackNameTranslation
+		mms.Translations = append(mms.Translations, TrackNameTranslation{
+			Language:    transVal.Language,
+			Translation: transVal.Translation,
+		})
+
+		if lang != "" && strings.EqualFold(transVal.Language, lang) && !mms.HasTranslation {
+			mms.HasTranslation = true
+			mms.TrackName = transVal.Translation
+			mms.ArtistName = track.ArtistName
+			log.Printf("found and processed translated data")
+		}
+	}
+}
+
+func fetchLyrics(reqCtx context.Context, trackID, commonTrackID string) (*MusicMixLyricsPayload, error) {
+	url := fmt.Sprintf("%vtrack.lyrics.get", constants.MUSIC_MIX_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"track_id":       trackID,
+		"commontrack_id": commonTrackID,
+		"apikey":         constants.MUSIC_MIX_API_KEY,
+		"page_size":      "1",
+	}
+	data := new(MusicMixLyricsPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTrackLyrics", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched lyrics data")
+
+	return data, nil
+}
+
+func processLyricsData(data *MusicMixLyricsPayload, mml *MusicMixLyricsResponse) error {
+	lyrics := data.Message.Body.Lyrics
+	if lyrics == nil {
+		return errors.New("error while processing lyrics vendor API data")
+	}
+
+	mml.LyricsText = parseLyricsBody(lyrics.LyricsBody)
+	mml.Copyright = lyrics.LyricsCopyright
+	mml.Explicit = lyrics.Explicit == 1
+	mml.Language = lyrics.LyricsLanguage
+
+	log.Printf("processed lyrics data")
+
+	return nil
+}
+
+// parseLyricsBody is used to split the lyrics body into stanzas of lines, the stanzas being separated by blank lines.
+// The Musixmatch disclaimer wrapped in asterisks, and the tracking ID which may follow it, are moved out of the lines.
+// The flat lyrics string joins the same lines, so that both forms hold the same text.
+// It returns lyrics text.
+func parseLyricsBody(lyric string) LyricsText {
+	var lt LyricsText
+
+	lyric = strings.Replace(lyric, "\r\n", "\n", -1)
+	if match := disclaimerRegex.FindStringSubmatch(lyric); match != nil {
+		lt.Disclaimer = match[1]
+		lyric = disclaimerRegex.ReplaceAllString(lyric, "")
+	}
+
+	lines := make([]string, 0)
+	for _, stanza := range stanzaSeparatorRegex.Split(strings.TrimSpace(lyric), -1) {
+		stanzaLines := make([]string, 0)
+		for _, line := range strings.Split(stanza, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				stanzaLines = append(stanzaLines, line)
+			}
+		}
+
+		if len(stanzaLines) > 0 {
+			lt.Stanzas = append(lt.Stanzas, stanzaLines)
+			lines = append(lines, stanzaLines...)
+		}
+	}
+	lt.Lyrics = strings.Join(lines, ". ")
+
+	return lt
+}
+
+// GetLyricsForm is used to create a new lyrics form instance.
+// It returns lyrics form instance.
+func (lc *LyricsComponent) GetLyricsForm() *LyricsForm {
+	return new(LyricsForm)
+}
+
+// GetComponentAppError is used to retrieve app error from the component struct.
+// It returns app error of the component.
+func (lc *LyricsComponent) GetComponentAppError() *utils.AppError {
+	return lc.AppError
+}
+
+func (lc *LyricsComponent) SetComponentAppError(status int, err error) {
+	lc.AppError = &utils.AppError{
+		Status: status,
+		Error:  err,
+	}
+}
+
+// Valid validates and normalizes the lyrics form, either track and artist or a Musixmatch track ID is required.
+func (f *LyricsForm) Valid() error {
+	// The track and artist are only sent as query params, so they are not HTML escaped, else titles with &, < or ' would not match.
+	f.Track = strings.TrimSpace(f.Track)
+	f.Artist = strings.TrimSpace(f.Artist)
+	var err error
+	if f.Lang, err = validLang(f.Lang); err != nil {
+		return err
+	}
+
+	if f.TrackID < 0 || f.CommonTrackID < 0 {
+		return errors.New("`track_id` and `commontrack_id` parameters should be positive")
+	} else if f.TrackID == 0 && f.CommonTrackID == 0 && (f.Track == "" || f.Artist == "") {
+		return errors.New("`track` and `artist` parameters are required when `track_id` or `commontrack_id` is not given")
+	}
+
+	return nil
+}
+
+func init() {
+	components.ComponentMap["Lyrics"] = func(bc *components.BaseComponent) interface{} {
+		c := &LyricsComponent{BaseComponent: *bc}
+
+		return Lyrics(c)
+	}
+}
+
+// validLang normalizes the given ISO 639-1 language code, which may be empty.
+// It returns the normalized language and error if it is not a two letter code.
+func validLang(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang != "" && !langRegex.MatchString(lang) {
+		return "", errors.New("`lang` parameter is invalid, it should be a two letter ISO 639-1 language code")
+	}
+
+	return lang, nil
+}
@@ -0,0 +1,516 @@
+package track
+
+import (
+	"context"
+	"testing"
+
+	"geomelody/components"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLyricsForm_Valid(t *testing.T) {
+	type vars struct {
+		form *LyricsForm
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   *LyricsForm
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should fail when artist is missing",
+			vars: vars{
+				form: &LyricsForm{
+					Track: "Yellow",
+				},
+			},
+			hasErr: true,
+			err:    "`track` and `artist` parameters are required",
+		},
+		{
+			name: "should fail when track ID is negative",
+			vars: vars{
+				form: &LyricsForm{
+					TrackID: -1,
+				},
+			},
+			hasErr: true,
+			err:    "`track_id` and `commontrack_id` parameters should be positive",
+		},
+		{
+			name: "should fail when lang is invalid",
+			vars: vars{
+				form: &LyricsForm{
+					TrackID: 84584600,
+					Lang:    "japanese",
+				},
+			},
+			hasErr: true,
+			err:    "`lang` parameter is invalid",
+		},
+		{
+			name: "should success with track and artist",
+			vars: vars{
+				form: &LyricsForm{
+					Track:  " Yellow ",
+					Artist: "Coldplay",
+				},
+			},
+			want: &LyricsForm{
+				Track:  "Yellow",
+				Artist: "Coldplay",
+			},
+		},
+		{
+			name: "should success and keep the special characters of track and artist unescaped",
+			vars: vars{
+				form: &LyricsForm{
+					Track:  " Don't Stop Me Now ",
+					Artist: "Queen & <Friends>",
+				},
+			},
+			want: &LyricsForm{
+				Track:  "Don't Stop Me Now",
+				Artist: "Queen & <Friends>",
+			},
+		},
+		{
+			name: "should success and normalize the lang",
+			vars: vars{
+				form: &LyricsForm{
+					TrackID: 84584600,
+					Lang:    " JA ",
+				},
+			},
+			want: &LyricsForm{
+				TrackID: 84584600,
+				Lang:    "ja",
+			},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+
+			// Run test
+			err := form.Valid()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Exactlyf(t, tCase.want, form, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestLyricsComponent_GetLyrics(t *testing.T) {
+	type vars struct {
+		component components.BaseComponent
+
+		form *LyricsForm
+
+		headers map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		hasLyrics          bool
+		untranslatedLyrics bool
+		hasErr             bool
+		err                string
+	}{
+		{
+			name: "should success to fetch the lyrics by track ID",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &LyricsForm{
+					TrackID: 84584600,
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			hasLyrics: true,
+		},
+		{
+			name: "should success to fetch the lyrics along with the translation",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &LyricsForm{
+					TrackID: 84584600,
+					Lang:    "ja",
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			hasLyrics: true,
+		},
+		{
+			name: "should success to fetch the flat lyrics along with the stanzas",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &LyricsForm{
+					TrackID:    84584600,
+					Lang:       "ja",
+					FlatLyrics: true,
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			hasLyrics: true,
+		},
+		{
+			name: "should success and keep the original lyrics when the translation is not found",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &LyricsForm{
+					TrackID: 84584600,
+					Lang:    "ja",
+				},
+				headers: map[string]string{
+					"x-mock-api":                           "default",
+					"x-mock-api-getmusicmixartist":         "not_found",
+					"x-mock-api-gettracklyricstranslation": "not_found",
+				},
+			},
+			hasLyrics:          true,
+			untranslatedLyrics: true,
+		},
+		{
+			name: "should success to fetch the track without lyrics by track and artist",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &LyricsForm{
+					Track:  "Yellow",
+					Artist: "Coldplay",
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+		},
+		{
+			name: "should fail to fetch the lyrics",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &LyricsForm{
+					TrackID: 84584600,
+				},
+				headers: map[string]string{
+					"x-mock-api": "error_response",
+				},
+			},
+			hasErr: true,
+			err:    "error",
+		},
+		{
+			name: "should fail when the lyrics payload does not match the schema",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &LyricsForm{
+					TrackID: 84584600,
+				},
+				headers: map[string]string{
+					"x-mock-api":                "default",
+					"x-mock-api-gettracklyrics": "invalid_schema",
+				},
+			},
+			hasErr: true,
+			err:    "error while decoding GetTrackLyrics vendor API data",
+		},
+		{
+			name: "should fail when the lyrics quota is exceeded",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &LyricsForm{
+					TrackID: 84584600,
+				},
+				headers: map[string]string{
+					"x-mock-api":                "default",
+					"x-mock-api-gettracklyrics": "quota_exceeded",
+				},
+			},
+			hasErr: true,
+			err:    "GetTrackLyrics vendor API error, quota exceeded (code 402)",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+			lc := &LyricsComponent{
+				BaseComponent: tCase.vars.component,
+			}
+			ctx := lc.ReqCtx
+			ctx = context.WithValue(ctx, "x-mock-headers", tCase.vars.headers)
+			lc.ReqCtx = ctx
+
+			// Run test
+			got, err := lc.GetLyrics(form)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Equalf(t, tCase.hasLyrics, got.HasLyrics, "case: %v", tCase)
+				if tCase.hasLyrics {
+					assert.Equalf(t, "Yellow", got.Track.Name, "case: %v", tCase)
+					assert.Equalf(t, "en", got.Language, "case: %v", tCase)
+					if assert.Lenf(t, got.Stanzas, 2, "case: %v", tCase) {
+						assert.Equalf(t, "Look at the stars", got.Stanzas[0][0], "case: %v", tCase)
+						assert.Equalf(t, []string{"I came along", "I wrote a song for you", "..."}, got.Stanzas[1], "case: %v", tCase)
+					}
+					assert.Equalf(t, "This Lyrics is NOT for Commercial use", got.Disclaimer, "case: %v", tCase)
+					assert.NotEmptyf(t, got.Copyright, "case: %v", tCase)
+					if form.FlatLyrics {
+						assert.Containsf(t, got.Lyrics, "Look at the stars", "case: %v", tCase)
+						assert.NotContainsf(t, got.Lyrics, "NOT for Commercial use", "case: %v", tCase)
+					} else {
+						assert.Emptyf(t, got.Lyrics, "case: %v", tCase)
+					}
+					assert.Lenf(t, got.Translation.Translations, 2, "case: %v", tCase)
+					if tCase.untranslatedLyrics {
+						assert.Equalf(t, "ja", got.Translation.Language, "case: %v", tCase)
+						assert.Equalf(t, "イエロー", got.Translation.TrackName, "case: %v", tCase)
+						assert.Equalf(t, "Coldplay", got.Translation.ArtistName, "case: %v", tCase)
+						assert.Falsef(t, got.Translation.LyricsTranslated, "case: %v", tCase)
+						assert.Equalf(t, got.Stanzas, got.Translation.Stanzas, "case: %v", tCase)
+					} else if form.Lang != "" {
+						assert.Equalf(t, "ja", got.Translation.Language, "case: %v", tCase)
+						assert.Equalf(t, "イエロー", got.Translation.TrackName, "case: %v", tCase)
+						assert.Equalf(t, "コールドプレイ", got.Translation.ArtistName, "case: %v", tCase)
+						assert.Truef(t, got.Translation.LyricsTranslated, "case: %v", tCase)
+						assert.Equalf(t, [][]string{{"星を見て", "あなたのためにどんなに輝いているか"}}, got.Translation.Stanzas, "case: %v", tCase)
+						if form.FlatLyrics {
+							assert.Containsf(t, got.Translation.Lyrics, "星を見て", "case: %v", tCase)
+						} else {
+							assert.Emptyf(t, got.Translation.Lyrics, "case: %v", tCase)
+						}
+					} else {
+						assert.Emptyf(t, got.Translation.Stanzas, "case: %v", tCase)
+					}
+				} else {
+					assert.Emptyf(t, got.Stanzas, "case: %v", tCase)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLyricsBody(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		lyric string
+
+		want LyricsText
+	}{
+		{
+			name:  "should move the disclaimer and the tracking ID out of both the stanzas and the flat lyrics",
+			lyric: "Look at the stars\r\nLook how they shine for you\r\n\r\nI came along\n\n******* This Lyrics is NOT for Commercial use *******\n(1409624248346)",
+			want: LyricsText{
+				Lyrics:     "Look at the stars. Look how they shine for you. I came along",
+				Stanzas:    [][]string{{"Look at the stars", "Look how they shine for you"}, {"I came along"}},
+				Disclaimer: "This Lyrics is NOT for Commercial use",
+			},
+		},
+		{
+			name:  "should accept a disclaimer of any wording",
+			lyric: "Look at the stars\n*** Lyrics not for commercial use ***",
+			want: LyricsText{
+				Lyrics:     "Look at the stars",
+				Stanzas:    [][]string{{"Look at the stars"}},
+				Disclaimer: "Lyrics not for commercial use",
+			},
+		},
+		{
+			name:  "should keep the lyrics without disclaimer",
+			lyric: "Look at the stars (yeah)",
+			want: LyricsText{
+				Lyrics:  "Look at the stars (yeah)",
+				Stanzas: [][]string{{"Look at the stars (yeah)"}},
+			},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Run test
+			got := parseLyricsBody(tCase.lyric)
+
+			// Assert
+			assert.Equalf(t, tCase.want, got, "case: %v", tCase)
+		})
+	}
+}
+
+func TestLyricsComponent_GetSyncedLyrics(t *testing.T) {
+	type vars struct {
+		component components.BaseComponent
+
+		form *SyncedLyricsForm
+
+		headers map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		source string
+		lines  int
+		lrc    string
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to fetch the subtitle of the track",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &SyncedLyricsForm{
+					LyricsForm: LyricsForm{
+						TrackID: 84584600,
+					},
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			source: subtitleSource,
+			lines:  4,
+			lrc:    "[ti:Yellow]\n[ar:Coldplay]\n[al:Parachutes]\n[length:04:27]\n[00:16.32]Look at the stars\n[00:20.10]Look how they shine for you\n[00:25.47]And everything you do\n[00:31.90]\n",
+		},
+		{
+			name: "should success to fetch the richsync of the track",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &SyncedLyricsForm{
+					LyricsForm: LyricsForm{
+						TrackID: 84584600,
+					},
+					Source: " RichSync ",
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			source: richsyncSource,
+			lines:  2,
+		},
+		{
+			name: "should fail when the track has no synced lyrics",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &SyncedLyricsForm{
+					LyricsForm: LyricsForm{
+						Track:  "Yellow",
+						Artist: "Coldplay",
+					},
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			hasErr: true,
+			err:    "synced lyrics are not available for the track",
+		},
+		{
+			name: "should fail when source is invalid",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &SyncedLyricsForm{
+					LyricsForm: LyricsForm{
+						TrackID: 84584600,
+					},
+					Source: "karaoke",
+				},
+			},
+			hasErr: true,
+			err:    "`source` parameter is invalid",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+			lc := &LyricsComponent{
+				BaseComponent: tCase.vars.component,
+			}
+			ctx := lc.ReqCtx
+			ctx = context.WithValue(ctx, "x-mock-headers", tCase.vars.headers)
+			lc.ReqCtx = ctx
+
+			// Run test
+			got, err := lc.GetSyncedLyrics(form)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Equalf(t, tCase.source, got.Source, "case: %v", tCase)
+				assert.Equalf(t, "en", got.Language, "case: %v", tCase)
+				if assert.Lenf(t, got.Lines, tCase.lines, "case: %v", tCase) {
+					assert.Equalf(t, 16.32, got.Lines[0].Time, "case: %v", tCase)
+					assert.Equalf(t, "Look at the stars", got.Lines[0].Text, "case: %v", tCase)
+				}
+				if tCase.source == richsyncSource {
+					assert.Equalf(t, 19.85, got.Lines[0].End, "case: %v", tCase)
+					assert.Lenf(t, got.Lines[0].Words, 4, "case: %v", tCase)
+				} else {
+					assert.Equalf(t, tCase.lrc, got.LRC(), "case: %v", tCase)
+				}
+			}
+		})
+	}
+}
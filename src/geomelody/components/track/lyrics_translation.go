@@ -0,0 +1,142 @@
+package track
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"geomelody/constants"
+	"geomelody/utils"
+)
+
+// fetchTranslation is used to translate the track name, the artist name and the lyrics of the matched track in the given language.
+// The original values are kept for the ones that are not translated, the vendor errors being only logged, so that
+// a missing translation never fails the lyrics.
+// It returns lyrics translation.
+func fetchTranslation(reqCtx context.Context, mms *MusicMixSearchResponse, lyrics LyricsText, lyricsLang, lang string) LyricsTranslation {
+	lt := LyricsTranslation{
+		Language:   lang,
+		TrackName:  mms.TrackName,
+		ArtistName: mms.ArtistName,
+	}
+
+	for _, translation := range mms.Translations {
+		if strings.EqualFold(translation.Language, lang) {
+			lt.TrackName = translation.Translation
+			break
+		}
+	}
+
+	if mms.ArtistID != 0 {
+		if data, err := fetchMusicMixArtist(reqCtx, strconv.Itoa(mms.ArtistID)); err != nil {
+			log.Printf("error fetching the artist translation, hence the original name is kept: %v", err)
+		} else if artistName := processArtistTranslationData(data, lang); artistName != "" {
+			lt.ArtistName = artistName
+		}
+	}
+
+	if !mms.HasLyrics {
+		return lt
+	}
+
+	lt.Lyrics = lyrics.Lyrics
+	lt.Stanzas = lyrics.Stanzas
+	if strings.EqualFold(lyricsLang, lang) {
+		lt.LyricsTranslated = true
+
+		return lt
+	}
+
+	data, err := fetchLyricsTranslation(reqCtx, strconv.Itoa(mms.TrackID), strconv.Itoa(mms.CommonTrackID), lang)
+	var translated LyricsText
+	if err == nil {
+		translated, err = processLyricsTranslationData(data)
+	}
+	if err != nil {
+		log.Printf("error fetching the lyrics translation, hence the original lyrics are kept: %v", err)
+
+		return lt
+	}
+
+	lt.LyricsTranslated = true
+	lt.Lyrics = translated.Lyrics
+	lt.Stanzas = translated.Stanzas
+
+	return lt
+}
+
+// fetchMusicMixArtist is used to fetch the artist details of the given Musixmatch artist ID from MUSIC MIX API.
+// It returns API response and error.
+func fetchMusicMixArtist(reqCtx context.Context, artistID string) (*MusicMixArtistPayload, error) {
+	url := fmt.Sprintf("%vartist.get", constants.MUSIC_MIX_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"artist_id": artistID,
+		"apikey":    constants.MUSIC_MIX_API_KEY,
+	}
+	data := new(MusicMixArtistPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetMusicMixArtist", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched music mix artist data")
+
+	return data, nil
+}
+
+// processArtistTranslationData is used to read the artist name translated in the given language from artist.get API data.
+// It returns translated artist name or empty string if there is no such translation.
+func processArtistTranslationData(data *MusicMixArtistPayload, lang string) string {
+	artist := data.Message.Body.Artist
+	if artist == nil {
+		return ""
+	}
+
+	for _, translation := range artist.ArtistNameTranslationList {
+		if transVal := translation.ArtistNameTranslation; strings.EqualFold(transVal.Language, lang) {
+			log.Printf("processed artist translation data")
+
+			return transVal.Translation
+		}
+	}
+
+	return ""
+}
+
+// fetchLyricsTranslation is used to fetch the lyrics of the given track translated in the given language from MUSIC MIX API.
+// It returns API response and error.
+func fetchLyricsTranslation(reqCtx context.Context, trackID, commonTrackID, lang string) (*MusicMixLyricsPayload, error) {
+	url := fmt.Sprintf("%vtrack.lyrics.translation.get", constants.MUSIC_MIX_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"track_id":          trackID,
+		"commontrack_id":    commonTrackID,
+		"selected_language": lang,
+		"apikey":            constants.MUSIC_MIX_API_KEY,
+	}
+	data := new(MusicMixLyricsPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTrackLyricsTranslation", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched lyrics translation data")
+
+	return data, nil
+}
+
+// processLyricsTranslationData is used to read the translated lyrics of a track.lyrics.translation.get API data.
+// It returns translated lyrics text and error.
+func processLyricsTranslationData(data *MusicMixLyricsPayload) (LyricsText, error) {
+	lyrics := data.Message.Body.Lyrics
+	if lyrics == nil || lyrics.LyricsTranslated == nil {
+		return LyricsText{}, errors.New("error while processing lyrics translation vendor API data")
+	}
+
+	log.Printf("processed lyrics translation data")
+
+	return parseLyricsBody(lyrics.LyricsTranslated.LyricsBody), nil
+}
@@ -0,0 +1,184 @@
+package track
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"geomelody/utils"
+)
+
+type MusicMixHeader struct {
+	StatusCode int `json:"status_code"`
+}
+
+type MusicMixNameTranslation struct {
+	Language    string `json:"language"`
+	Translation string `json:"translation"`
+}
+
+type MusicMixTrack struct {
+	TrackID       int    `json:"track_id"`
+	CommonTrackID int    `json:"commontrack_id"`
+	TrackName     string `json:"track_name"`
+	ArtistID      int    `json:"artist_id"`
+	ArtistName    string `json:"artist_name"`
+	AlbumID       int    `json:"album_id"`
+	AlbumName     string `json:"album_name"`
+	Explicit      int    `json:"explicit"`
+	HasLyrics     int    `json:"has_lyrics"`
+	HasSubtitles  int    `json:"has_subtitles"`
+	HasRichsync   int    `json:"has_richsync"`
+
+	TrackNameTranslationList []struct {
+		TrackNameTranslation MusicMixNameTranslation `json:"track_name_translation"`
+	} `json:"track_name_translation_list"`
+}
+
+// MusicMixTrackPayload is the track.search or track.get API data, the former listing the matched tracks.
+type MusicMixTrackPayload struct {
+	Message struct {
+		Header MusicMixHeader `json:"header"`
+		Body   struct {
+			TrackList []struct {
+				Track MusicMixTrack `json:"track"`
+			} `json:"track_list"`
+			Track *MusicMixTrack `json:"track"`
+		} `json:"body"`
+	} `json:"message"`
+}
+
+type MusicMixLyrics struct {
+	Explicit        int    `json:"explicit"`
+	LyricsBody      string `json:"lyrics_body"`
+	LyricsLanguage  string `json:"lyrics_language"`
+	LyricsCopyright string `json:"lyrics_copyright"`
+
+	LyricsTranslated *struct {
+		Language   string `json:"language"`
+		LyricsBody string `json:"lyrics_body"`
+	} `json:"lyrics_translated"`
+}
+
+// MusicMixLyricsPayload is the track.lyrics.get or track.lyrics.translation.get API data.
+type MusicMixLyricsPayload struct {
+	Message struct {
+		Header MusicMixHeader `json:"header"`
+		Body   struct {
+			Lyrics *MusicMixLyrics `json:"lyrics"`
+		} `json:"body"`
+	} `json:"message"`
+}
+
+// MusicMixArtistPayload is the artist.get API data.
+type MusicMixArtistPayload struct {
+	Message struct {
+		Header MusicMixHeader `json:"header"`
+		Body   struct {
+			Artist *struct {
+				ArtistID   int    `json:"artist_id"`
+				ArtistName string `json:"artist_name"`
+
+				ArtistNameTranslationList []struct {
+					ArtistNameTranslation MusicMixNameTranslation `json:"artist_name_translation"`
+				} `json:"artist_name_translation_list"`
+			} `json:"artist"`
+		} `json:"body"`
+	} `json:"message"`
+}
+
+// MusicMixSubtitlePayload is the track.subtitle.get API data, the subtitle body being a JSON encoded list of MusicMixSubtitleLine.
+type MusicMixSubtitlePayload struct {
+	Message struct {
+		Header MusicMixHeader `json:"header"`
+		Body   struct {
+			Subtitle *struct {
+				SubtitleBody     string        `json:"subtitle_body"`
+				SubtitleLanguage string        `json:"subtitle_language"`
+				SubtitleLength   utils.FlexInt `json:"subtitle_length"`
+			} `json:"subtitle"`
+		} `json:"body"`
+	} `json:"message"`
+}
+
+// MusicMixRichsyncPayload is the track.richsync.get API data, the richsync body being a JSON encoded list of MusicMixRichsyncLine.
+type MusicMixRichsyncPayload struct {
+	Message struct {
+		Header MusicMixHeader `json:"header"`
+		Body   struct {
+			Richsync *struct {
+				RichsyncBody     string        `json:"richsync_body"`
+				RichsyncLanguage string        `json:"richsync_language"`
+				RichsyncLength   utils.FlexInt `json:"richsync_length"`
+			} `json:"richsync"`
+		} `json:"body"`
+	} `json:"message"`
+}
+
+type MusicMixSubtitleLine struct {
+	Text string `json:"text"`
+	Time struct {
+		Total float64 `json:"total"`
+	} `json:"time"`
+}
+
+type MusicMixRichsyncLine struct {
+	Start float64 `json:"ts"`
+	End   float64 `json:"te"`
+	Text  string  `json:"x"`
+	Words []struct {
+		Char   string  `json:"c"`
+		Offset float64 `json:"o"`
+	} `json:"l"`
+}
+
+// MusicMixProvider is the lyrics provider backed by MUSIC MIX API.
+type MusicMixProvider struct{}
+
+// TrackLyrics is used to search the given track and fetch its lyrics, along with their translation in the given language.
+// The names which MUSIC MIX API does not translate are the ones the track was searched with.
+// It returns the translation, or the English track name if no language is given and MUSIC MIX API has one, and error.
+func (MusicMixProvider) TrackLyrics(ctx context.Context, trackName, artistName, lang string, lt *LyricsText) (*LyricsTranslation, error) {
+	var err error
+	var trackData *MusicMixTrackPayload
+	var lyricsData *MusicMixLyricsPayload
+	musicMixResp := new(MusicMixSearchResponse)
+	if trackData, err = fetchTrackID(ctx, artistName, trackName); err != nil {
+		return nil, err
+	} else if err = processTrackIDData(trackData, musicMixResp, ""); err != nil {
+		return nil, err
+	} else if musicMixResp.TrackID == 0 && musicMixResp.CommonTrackID == 0 {
+		return nil, nil
+	}
+
+	musicMixLyrics := new(MusicMixLyricsResponse)
+	if musicMixResp.HasLyrics {
+		if lyricsData, err = fetchLyrics(ctx, strconv.Itoa(musicMixResp.TrackID), strconv.Itoa(musicMixResp.CommonTrackID)); err != nil {
+			return nil, err
+		} else if err = processLyricsData(lyricsData, musicMixLyrics); err != nil {
+			return nil, err
+		}
+		*lt = musicMixLyrics.LyricsText
+	}
+
+	if lang == "" {
+		// Only the track name is looked up, so that the top tracks asked without a language cost no more vendor calls.
+		for _, translation := range musicMixResp.Translations {
+			if strings.EqualFold(translation.Language, defaultLang) {
+				return &LyricsTranslation{Language: defaultLang, TrackName: translation.Translation, ArtistName: artistName}, nil
+			}
+		}
+
+		return nil, nil
+	}
+
+	translation := fetchTranslation(ctx, musicMixResp, musicMixLyrics.LyricsText, musicMixLyrics.Language, lang)
+	if translation.TrackName == musicMixResp.TrackName {
+		translation.TrackName = trackName
+	}
+	if translation.ArtistName == musicMixResp.ArtistName {
+		translation.ArtistName = artistName
+	}
+
+	return &translation, nil
+}
@@ -0,0 +1,120 @@
+package track
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"geomelody/components/artist"
+	"geomelody/constants"
+)
+
+// ChartProvider fetches the top track of the charts.
+type ChartProvider interface {
+	RegionalTopTrack(ctx context.Context, country string, rttr *RegionalTopTrackResponse) error
+	GlobalTopTrack(ctx context.Context, rttr *RegionalTopTrackResponse) error
+	TagTopTrack(ctx context.Context, tag string, rttr *RegionalTopTrackResponse) error
+}
+
+// ArtistProvider fetches the details of an artist.
+type ArtistProvider interface {
+	ArtistInfo(ctx context.Context, artistName string, ai *artist.ArtistInfo) error
+}
+
+// LyricsProvider fetches the lyrics of a track.
+// It returns the names of the track and artist, and the lyrics, translated in the given language when the provider has
+// them. Only the names are translated, in English, when the language is empty, and nil is returned if there are none.
+type LyricsProvider interface {
+	TrackLyrics(ctx context.Context, trackName, artistName, lang string, lt *LyricsText) (*LyricsTranslation, error)
+}
+
+// SimilarityProvider fetches the tracks similar to a track.
+type SimilarityProvider interface {
+	SimilarTracks(ctx context.Context, trackName, artistName string) ([]TrackSuggestion, error)
+}
+
+// Providers holds the sources the top track and its enrichment are fetched from.
+type Providers struct {
+	Chart      ChartProvider
+	Artist     ArtistProvider
+	Lyrics     LyricsProvider
+	Similarity SimilarityProvider
+}
+
+const (
+	lastProvider     = "last"
+	musicMixProvider = "musicmix"
+)
+
+// The providers available by name, new sources register themselves here from their init function.
+var (
+	ChartProviders      = map[string]ChartProvider{lastProvider: LastFMProvider{}}
+	ArtistProviders     = map[string]ArtistProvider{lastProvider: LastFMProvider{}}
+	LyricsProviders     = map[string]LyricsProvider{musicMixProvider: MusicMixProvider{}}
+	SimilarityProviders = map[string]SimilarityProvider{lastProvider: LastFMProvider{}}
+)
+
+// configuredProviders are the providers used by the components which are not given their own providers.
+var configuredProviders = Providers{
+	Chart:      LastFMProvider{},
+	Artist:     LastFMProvider{},
+	Lyrics:     MusicMixProvider{},
+	Similarity: LastFMProvider{},
+}
+
+// InitProviders selects the providers named in the env, keeping LAST API and MUSIC MIX API for the unset ones.
+// It returns error if a provider is unknown.
+func InitProviders() error {
+	p := configuredProviders
+	var ok bool
+
+	if name := constants.CHART_PROVIDER; name != "" {
+		if p.Chart, ok = ChartProviders[name]; !ok {
+			return fmt.Errorf("unknown chart provider: %v", name)
+		}
+	}
+	if name := constants.ARTIST_PROVIDER; name != "" {
+		if p.Artist, ok = ArtistProviders[name]; !ok {
+			return fmt.Errorf("unknown artist provider: %v", name)
+		}
+	}
+	if name := constants.LYRICS_PROVIDER; name != "" {
+		if p.Lyrics, ok = LyricsProviders[name]; !ok {
+			return fmt.Errorf("unknown lyrics provider: %v", name)
+		}
+	}
+	if name := constants.SIMILARITY_PROVIDER; name != "" {
+		if p.Similarity, ok = SimilarityProviders[name]; !ok {
+			return fmt.Errorf("unknown similarity provider: %v", name)
+		}
+	}
+
+	configuredProviders = p
+	log.Printf("Initialized track providers")
+
+	return nil
+}
+
+// orConfigured is used to fill the providers which are not set with the configured ones.
+// It returns providers.
+func (p *Providers) orConfigured() Providers {
+	if p == nil {
+		return configuredProviders
+	}
+
+	res := *p
+	if res.Chart == nil {
+		res.Chart = configuredProviders.Chart
+	}
+	if res.Artist == nil {
+		res.Artist = configuredProviders.Artist
+	}
+	if res.Lyrics == nil {
+		res.Lyrics = configuredProviders.Lyrics
+	}
+	if res.Similarity == nil {
+		res.Similarity = configuredProviders.Similarity
+	}
+
+	return res
+}
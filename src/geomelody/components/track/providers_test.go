@@ -0,0 +1,250 @@
+package track
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"geomelody/components"
+	"geomelody/components/artist"
+	"geomelody/constants"
+	"geomelody/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProvider is a local chart, artist, lyrics and similarity provider, failing the steps whose error is set.
+type fakeProvider struct {
+	chartErr      error
+	artistErr     error
+	lyricsErr     error
+	similarityErr error
+
+	translated *LyricsTranslation
+}
+
+func (f fakeProvider) RegionalTopTrack(_ context.Context, country string, rttr *RegionalTopTrackResponse) error {
+	rttr.Meta.Country = country
+
+	return f.GlobalTopTrack(context.Background(), rttr)
+}
+
+func (f fakeProvider) GlobalTopTrack(_ context.Context, rttr *RegionalTopTrackResponse) error {
+	if f.chartErr != nil {
+		return f.chartErr
+	}
+	rttr.Track.Rank = 1
+	rttr.Track.Name = "Kesariya"
+	rttr.Track.ArtistsInfo.Name = "Arijit Singh"
+
+	return nil
+}
+
+func (f fakeProvider) TagTopTrack(_ context.Context, _ string, rttr *RegionalTopTrackResponse) error {
+	return f.GlobalTopTrack(context.Background(), rttr)
+}
+
+func (f fakeProvider) ArtistInfo(_ context.Context, artistName string, ai *artist.ArtistInfo) error {
+	if f.artistErr != nil {
+		return f.artistErr
+	}
+	ai.Summary = "summary of " + artistName
+
+	return nil
+}
+
+func (f fakeProvider) TrackLyrics(_ context.Context, trackName, _, _ string, lt *LyricsText) (*LyricsTranslation, error) {
+	if f.lyricsErr != nil {
+		return nil, f.lyricsErr
+	}
+	lt.Stanzas = [][]string{{"lyrics of " + trackName}}
+
+	return f.translated, nil
+}
+
+func (f fakeProvider) SimilarTracks(_ context.Context, trackName, _ string) ([]TrackSuggestion, error) {
+	if f.similarityErr != nil {
+		return nil, f.similarityErr
+	}
+
+	return []TrackSuggestion{{Name: "similar to " + trackName}}, nil
+}
+
+func fakeProviders(f fakeProvider) *Providers {
+	return &Providers{
+		Chart:      f,
+		Artist:     f,
+		Lyrics:     f,
+		Similarity: f,
+	}
+}
+
+func TestTopTrackComponent_GetGlobalTopTrackWithProviders(t *testing.T) {
+	type vars struct {
+		component components.BaseComponent
+
+		providers *Providers
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		wantTrack       string
+		wantArtist      string
+		wantSuggestion  string
+		wantLyricsState EnrichmentStatus
+		hasErr          bool
+		err             string
+		status          int
+	}{
+		{
+			name: "should success to fetch the top track from the given providers",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				providers: fakeProviders(fakeProvider{}),
+			},
+			wantTrack:       "Kesariya",
+			wantArtist:      "Arijit Singh",
+			wantSuggestion:  "similar to Kesariya",
+			wantLyricsState: EnrichmentStatus{Status: http.StatusOK},
+		},
+		{
+			name: "should apply the translated names once the suggestions are fetched with the original names",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				providers: fakeProviders(fakeProvider{
+					translated: &LyricsTranslation{TrackName: "Saffron", ArtistName: "Arijit Singh (EN)"},
+				}),
+			},
+			wantTrack:       "Saffron",
+			wantArtist:      "Arijit Singh (EN)",
+			wantSuggestion:  "similar to Kesariya",
+			wantLyricsState: EnrichmentStatus{Status: http.StatusOK},
+		},
+		{
+			name: "should report the failed lyrics provider in the enrichment section",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				providers: fakeProviders(fakeProvider{
+					lyricsErr: &utils.VendorError{API: "FakeLyrics", Kind: utils.VendorUnavailable, Code: 503, Message: "down"},
+				}),
+			},
+			wantTrack:      "Kesariya",
+			wantArtist:     "Arijit Singh",
+			wantSuggestion: "similar to Kesariya",
+			wantLyricsState: EnrichmentStatus{
+				Status: http.StatusBadGateway,
+				Error:  "FakeLyrics vendor API error, unavailable (code 503): down",
+			},
+		},
+		{
+			name: "should fail when the chart provider fails",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				providers: fakeProviders(fakeProvider{
+					chartErr: &utils.VendorError{API: "FakeChart", Kind: utils.VendorNotFound, Code: 404, Message: "no chart"},
+				}),
+			},
+			hasErr: true,
+			err:    "FakeChart vendor API error, not found",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			ttc := &TopTrackComponent{
+				BaseComponent: tCase.vars.component,
+				Providers:     tCase.vars.providers,
+			}
+
+			// Run test
+			got, err := ttc.GetGlobalTopTrack(&GlobalTopTrackForm{})
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+					assert.Equalf(t, tCase.status, ttc.GetComponentAppError().Status, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Equalf(t, tCase.wantTrack, got.Track.Name, "case: %v", tCase)
+				assert.Equalf(t, tCase.wantArtist, got.Track.ArtistsInfo.Name, "case: %v", tCase)
+				assert.Equalf(t, "summary of Arijit Singh", got.Track.ArtistsInfo.Summary, "case: %v", tCase)
+				if assert.Lenf(t, got.TrackSuggestion, 1, "case: %v", tCase) {
+					assert.Equalf(t, tCase.wantSuggestion, got.TrackSuggestion[0].Name, "case: %v", tCase)
+				}
+				assert.Equalf(t, tCase.wantLyricsState, got.Enrichment.Lyrics, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestInitProviders(t *testing.T) {
+	type vars struct {
+		lyricsProvider string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to select the default providers",
+			vars: vars{},
+		},
+		{
+			name: "should success to select a registered provider",
+			vars: vars{
+				lyricsProvider: musicMixProvider,
+			},
+		},
+		{
+			name: "should fail when the provider is unknown",
+			vars: vars{
+				lyricsProvider: "unknown",
+			},
+			hasErr: true,
+			err:    "unknown lyrics provider: unknown",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			constants.LYRICS_PROVIDER = tCase.vars.lyricsProvider
+			defer func() {
+				constants.LYRICS_PROVIDER = ""
+			}()
+
+			// Run test
+			err := InitProviders()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.IsTypef(t, MusicMixProvider{}, configuredProviders.Lyrics, "case: %v", tCase)
+			}
+		})
+	}
+}
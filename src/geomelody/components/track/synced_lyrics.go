@@ -0,0 +1,286 @@
+package track
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"geomelody/components"
+	"geomelody/constants"
+	"geomelody/utils"
+)
+
+const (
+	subtitleSource = "subtitle"
+	richsyncSource = "richsync"
+)
+
+type SyncedLyricsForm struct {
+	LyricsForm
+	Source string `json:"source"`
+}
+
+type SyncedWord struct {
+	Offset float64 `json:"offset"`
+	Text   string  `json:"text"`
+}
+
+type SyncedLine struct {
+	Time  float64      `json:"time"`
+	End   float64      `json:"end,omitempty"`
+	Text  string       `json:"text"`
+	Words []SyncedWord `json:"words,omitempty"`
+}
+
+type SyncedLyricsResponse struct {
+	Track LyricsTrack `json:"track"`
+
+	Source       string       `json:"source"`
+	HasSubtitles bool         `json:"has_subtitles"`
+	HasRichsync  bool         `json:"has_richsync"`
+	Language     string       `json:"language"`
+	Length       int          `json:"length"`
+	Lines        []SyncedLine `json:"lines"`
+}
+
+// GetSyncedLyrics is used to fetch the time-synced lyrics of the given track and artist, or of the given Musixmatch track ID.
+// It returns synced lyrics data and error.
+func (lc *LyricsComponent) GetSyncedLyrics(form *SyncedLyricsForm) (*SyncedLyricsResponse, error) {
+	resp := new(SyncedLyricsResponse)
+	var err error
+	var data *MusicMixTrackPayload
+	if err = form.Valid(); err != nil {
+		lc.SetComponentAppError(http.StatusBadRequest, err)
+
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("synced-lyrics:%v:%v:%v:%v:%v", form.Source, form.TrackID, form.CommonTrackID, strings.ToLower(form.Artist), strings.ToLower(form.Track))
+	if components.IsRespInCache(form.UseCache, cacheKey, lc.Cache, resp) {
+		return resp, nil
+	}
+
+	musicMixResp := new(MusicMixSearchResponse)
+	if form.TrackID != 0 || form.CommonTrackID != 0 {
+		data, err = fetchTrack(lc.ReqCtx, strconv.Itoa(form.TrackID), strconv.Itoa(form.CommonTrackID))
+	} else {
+		data, err = fetchTrackID(lc.ReqCtx, form.Artist, form.Track)
+	}
+
+	if err != nil {
+		lc.SetComponentAppError(utils.StatusForError(err), err)
+	} else if err = processTrackIDData(data, musicMixResp, ""); err != nil {
+		lc.SetComponentAppError(utils.StatusForError(err), err)
+	} else if musicMixResp.TrackID == 0 && musicMixResp.CommonTrackID == 0 {
+		err = errors.New("track not found. Please check the input params")
+		lc.SetComponentAppError(http.StatusNotFound, err)
+	} else if resp.Source = syncedLyricsSource(form.Source, musicMixResp); resp.Source == "" {
+		err = errors.New("synced lyrics are not available for the track")
+		lc.SetComponentAppError(http.StatusNotFound, err)
+	} else if err = lc.fetchSyncedLyricsBody(musicMixResp, resp); err != nil {
+		lc.SetComponentAppError(utils.StatusForError(err), err)
+	} else {
+		components.CheckAndCacheResp(form.UseCache, cacheKey, lc.Cache, resp)
+	}
+
+	return resp, err
+}
+
+// syncedLyricsSource is used to pick the synced lyrics source of the matched track, the requested one if available,
+// else the subtitle followed by the richsync.
+// It returns the source or empty string if the track has no synced lyrics.
+func syncedLyricsSource(source string, mms *MusicMixSearchResponse) string {
+	switch {
+	case source == subtitleSource && mms.HasSubtitles, source == richsyncSource && mms.HasRichsync:
+		return source
+	case source != "":
+		return ""
+	case mms.HasSubtitles:
+		return subtitleSource
+	case mms.HasRichsync:
+		return richsyncSource
+	}
+
+	return ""
+}
+
+// fetchSyncedLyricsBody is used to fill the synced lyrics response from the matched track and fetch the synced lines
+// of the chosen source.
+// It returns error.
+func (lc *LyricsComponent) fetchSyncedLyricsBody(mms *MusicMixSearchResponse, slr *SyncedLyricsResponse) error {
+	slr.Track = mms.lyricsTrack()
+	slr.HasSubtitles = mms.HasSubtitles
+	slr.HasRichsync = mms.HasRichsync
+
+	trackID, commonTrackID := strconv.Itoa(mms.TrackID), strconv.Itoa(mms.CommonTrackID)
+	if slr.Source == richsyncSource {
+		if data, err := fetchRichsync(lc.ReqCtx, trackID, commonTrackID); err != nil {
+			return err
+		} else if err = processRichsyncData(data, slr); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if data, err := fetchSubtitle(lc.ReqCtx, trackID, commonTrackID); err != nil {
+		return err
+	} else if err = processSubtitleData(data, slr); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func fetchSubtitle(reqCtx context.Context, trackID, commonTrackID string) (*MusicMixSubtitlePayload, error) {
+	url := fmt.Sprintf("%vtrack.subtitle.get", constants.MUSIC_MIX_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"track_id":        trackID,
+		"commontrack_id":  commonTrackID,
+		"subtitle_format": "mxm",
+		"apikey":          constants.MUSIC_MIX_API_KEY,
+	}
+	data := new(MusicMixSubtitlePayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTrackSubtitle", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched subtitle data")
+
+	return data, nil
+}
+
+func fetchRichsync(reqCtx context.Context, trackID, commonTrackID string) (*MusicMixRichsyncPayload, error) {
+	url := fmt.Sprintf("%vtrack.richsync.get", constants.MUSIC_MIX_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"track_id":       trackID,
+		"commontrack_id": commonTrackID,
+		"apikey":         constants.MUSIC_MIX_API_KEY,
+	}
+	data := new(MusicMixRichsyncPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTrackRichsync", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched richsync data")
+
+	return data, nil
+}
+
+// processSubtitleData is used to read the timed lines of a track.subtitle.get API data in mxm format.
+// It returns error.
+func processSubtitleData(data *MusicMixSubtitlePayload, slr *SyncedLyricsResponse) error {
+	subtitle := data.Message.Body.Subtitle
+	if subtitle == nil {
+		return errors.New("error while processing subtitle vendor API data")
+	}
+
+	var lines []MusicMixSubtitleLine
+	if err := utils.DecodeJSON("GetTrackSubtitle", []byte(subtitle.SubtitleBody), &lines); err != nil {
+		return err
+	}
+
+	slr.Language = subtitle.SubtitleLanguage
+	slr.Length = int(subtitle.SubtitleLength)
+	slr.Lines = make([]SyncedLine, 0, len(lines))
+	for _, line := range lines {
+		slr.Lines = append(slr.Lines, SyncedLine{
+			Time: line.Time.Total,
+			Text: line.Text,
+		})
+	}
+
+	log.Printf("processed subtitle data")
+
+	return nil
+}
+
+// processRichsyncData is used to read the timed lines and words of a track.richsync.get API data.
+// It returns error.
+func processRichsyncData(data *MusicMixRichsyncPayload, slr *SyncedLyricsResponse) error {
+	richsync := data.Message.Body.Richsync
+	if richsync == nil {
+		return errors.New("error while processing richsync vendor API data")
+	}
+
+	var lines []MusicMixRichsyncLine
+	if err := utils.DecodeJSON("GetTrackRichsync", []byte(richsync.RichsyncBody), &lines); err != nil {
+		return err
+	}
+
+	slr.Language = richsync.RichsyncLanguage
+	slr.Length = int(richsync.RichsyncLength)
+	slr.Lines = make([]SyncedLine, 0, len(lines))
+	for _, line := range lines {
+		syncedLine := SyncedLine{
+			Time: line.Start,
+			End:  line.End,
+			Text: line.Text,
+		}
+		for _, word := range line.Words {
+			if strings.TrimSpace(word.Char) == "" {
+				continue
+			}
+			syncedLine.Words = append(syncedLine.Words, SyncedWord{
+				Offset: word.Offset,
+				Text:   word.Char,
+			})
+		}
+		slr.Lines = append(slr.Lines, syncedLine)
+	}
+
+	log.Printf("processed richsync data")
+
+	return nil
+}
+
+// LRC is used to render the synced lyrics in the LRC file format.
+// It returns the LRC file content.
+func (slr *SyncedLyricsResponse) LRC() string {
+	var sb strings.Builder
+	if slr.Track.Name != "" {
+		sb.WriteString(fmt.Sprintf("[ti:%v]\n", slr.Track.Name))
+	}
+	if slr.Track.ArtistName != "" {
+		sb.WriteString(fmt.Sprintf("[ar:%v]\n", slr.Track.ArtistName))
+	}
+	if slr.Track.AlbumName != "" {
+		sb.WriteString(fmt.Sprintf("[al:%v]\n", slr.Track.AlbumName))
+	}
+	if slr.Length > 0 {
+		sb.WriteString(fmt.Sprintf("[length:%02d:%02d]\n", slr.Length/60, slr.Length%60))
+	}
+
+	for _, line := range slr.Lines {
+		hundredths := int(line.Time*100 + 0.5)
+		sb.WriteString(fmt.Sprintf("[%02d:%02d.%02d]%v\n", hundredths/6000, hundredths/100%60, hundredths%100, line.Text))
+	}
+
+	return sb.String()
+}
+
+// GetSyncedLyricsForm is used to create a new synced lyrics form instance.
+// It returns synced lyrics form instance.
+func (lc *LyricsComponent) GetSyncedLyricsForm() *SyncedLyricsForm {
+	return new(SyncedLyricsForm)
+}
+
+// Valid validates and sanitizes the synced lyrics form.
+func (f *SyncedLyricsForm) Valid() error {
+	if err := f.LyricsForm.Valid(); err != nil {
+		return err
+	}
+
+	f.Source = strings.ToLower(strings.TrimSpace(f.Source))
+	if f.Source != "" && f.Source != subtitleSource && f.Source != richsyncSource {
+		return fmt.Errorf("`source` parameter is invalid, it should be either %v or %v", subtitleSource, richsyncSource)
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package track
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"geomelody/components"
+	"geomelody/constants"
+	"geomelody/utils"
+)
+
+type TagTopTrackComponent struct {
+	components.BaseComponent
+
+	// Providers are the sources of the top track, the configured ones are used when it is nil.
+	Providers *Providers
+}
+
+type TagTopTrack interface {
+	GetTagTopTrack(*TagTopTrackForm) (*RegionalTopTrackResponse, error)
+	GetTagTopTrackForm() *TagTopTrackForm
+	GetTagInfo(*TagInfoForm) (*TagInfoResponse, error)
+	GetTagInfoForm() *TagInfoForm
+	GetComponentAppError() *utils.AppError
+	SetComponentAppError(int, error)
+}
+
+type TagTopTrackForm struct {
+	Tag        string               `json:"tag"`
+	FlatLyrics bool                 `json:"flat_lyrics"`
+	Cache      components.CacheMode `json:"cache"`
+	// Lang is the ISO 639-1 language the names of the track are translated in when the lyrics provider has them, English by default.
+	Lang string `json:"lang"`
+	// UseCache is the legacy switch between the default and bypass cache modes, used when Cache is unset.
+	UseCache bool `json:"use_cache"`
+}
+
+type TagInfoForm struct {
+	Tag      string `json:"tag"`
+	UseCache bool   `json:"use_cache"`
+}
+
+type TagInfoResponse struct {
+	Name    string `json:"name"`
+	Total   int    `json:"total"`
+	Reach   int    `json:"reach"`
+	Summary string `json:"summary"`
+	Content string `json:"content"`
+}
+
+// GetTagTopTrack is used to call required external APIs to fetch the top track of the given tag along with the artists info, lyrics, and suggestions of the track.
+// It returns top track data and error.
+func (tttc *TagTopTrackComponent) GetTagTopTrack(form *TagTopTrackForm) (*RegionalTopTrackResponse, error) {
+	resp := new(RegionalTopTrackResponse)
+	var err error
+	if err = form.Valid(); err != nil {
+		tttc.SetComponentAppError(http.StatusBadRequest, err)
+
+		return nil, err
+	}
+
+	providers := tttc.Providers.orConfigured()
+	fetch := func(ctx context.Context, rttr *RegionalTopTrackResponse) error {
+		rttr.Meta.Chart = tagChart
+		rttr.Meta.Tag = form.Tag
+		if err := providers.Chart.TagTopTrack(ctx, form.Tag, rttr); err != nil {
+			return err
+		}
+		enrichTopTrack(ctx, providers, form.Lang, rttr)
+
+		return nil
+	}
+
+	cacheKey := topTrackCacheKey(fmt.Sprintf("tag:%v", form.Tag), form.Lang)
+	if serveCachedTopTrack(tttc.ReqCtx, form.Cache, cacheKey, tttc.Cache, resp, fetch) {
+		resp.formatLyrics(form.FlatLyrics)
+
+		return resp, nil
+	} else if form.Cache == components.CacheOnlyIfCached {
+		tttc.SetComponentAppError(http.StatusGatewayTimeout, components.ErrNotCached)
+
+		return nil, components.ErrNotCached
+	}
+
+	if err = fetch(tttc.ReqCtx, resp); err != nil {
+		tttc.SetComponentAppError(utils.StatusForError(err), err)
+	} else {
+		cacheTopTrack(form.Cache, cacheKey, tttc.Cache, resp)
+		resp.formatLyrics(form.FlatLyrics)
+	}
+
+	return resp, err
+}
+
+// GetTagInfo is used to fetch the description of the given tag.
+// It returns tag info data and error.
+func (tttc *TagTopTrackComponent) GetTagInfo(form *TagInfoForm) (*TagInfoResponse, error) {
+	resp := new(TagInfoResponse)
+	var err error
+	var data *LastFMTagPayload
+	if err = form.Valid(); err != nil {
+		tttc.SetComponentAppError(http.StatusBadRequest, err)
+
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("tag-info:%v", form.Tag)
+	if components.IsRespInCache(form.UseCache, cacheKey, tttc.Cache, resp) {
+		return resp, nil
+	}
+
+	if data, err = fetchTagInfo(tttc.ReqCtx, form.Tag); err != nil {
+		tttc.SetComponentAppError(utils.StatusForError(err), err)
+	} else if err = processTagInfo(data, resp); err != nil {
+		tttc.SetComponentAppError(utils.StatusForError(err), err)
+	} else {
+		components.CheckAndCacheResp(form.UseCache, cacheKey, tttc.Cache, resp)
+	}
+
+	return resp, err
+}
+
+// fetchTagTopTrackData is used to fetch a page of top tracks of the given tag from LAST API.
+// It returns API response and error.
+func fetchTagTopTrackData(reqCtx context.Context, tag, limit, page string) (*LastFMTracksPayload, error) {
+	url := fmt.Sprintf("%v", constants.LAST_API_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"method":  "tag.gettoptracks",
+		"tag":     tag,
+		"api_key": constants.LAST_API_KEY,
+		"format":  "json",
+		"limit":   limit,
+		"page":    page,
+	}
+	data := new(LastFMTagTracksPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTopTrackByTag", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched tag track data")
+
+	return &data.LastFMTracksPayload, nil
+}
+
+// fetchTagInfo is used to fetch the details of the given tag from LAST API.
+// It returns API response and error.
+func fetchTagInfo(reqCtx context.Context, tag string) (*LastFMTagPayload, error) {
+	url := fmt.Sprintf("%v", constants.LAST_API_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"method":  "tag.getinfo",
+		"tag":     tag,
+		"api_key": constants.LAST_API_KEY,
+		"format":  "json",
+	}
+	data := new(LastFMTagPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTagInfo", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched tag info data")
+
+	return data, nil
+}
+
+// processTagTrackData is used to read the first track of a tag.gettoptracks API data.
+// Unlike geo.gettoptracks, the ranks of tag.gettoptracks are already 1-based.
+// It returns error.
+func processTagTrackData(data *LastFMTracksPayload, rttr *RegionalTopTrackResponse) error {
+	if err := processRegionalTrackData(data, rttr); err != nil {
+		return err
+	}
+	rttr.Track.Rank--
+
+	return nil
+}
+
+func processTagInfo(data *LastFMTagPayload, tir *TagInfoResponse) error {
+	tag := data.Tag
+	if tag == nil {
+		return errors.New("received empty tag data from vendor API. Please check input params")
+	}
+
+	tir.Name = tag.Name
+	tir.Total = int(tag.Total)
+	tir.Reach = int(tag.Reach)
+	tir.Summary = strings.Replace(tag.Wiki.Summary, "\n", ". ", -1)
+	tir.Content = tag.Wiki.Content
+
+	log.Printf("processed tag info data")
+
+	return nil
+}
+
+// GetTagTopTrackForm is used to create a new tag top track form instance.
+// It returns tag top track form instance.
+func (tttc *TagTopTrackComponent) GetTagTopTrackForm() *TagTopTrackForm {
+	return new(TagTopTrackForm)
+}
+
+// GetTagInfoForm is used to create a new tag info form instance.
+// It returns tag info form instance.
+func (tttc *TagTopTrackComponent) GetTagInfoForm() *TagInfoForm {
+	return new(TagInfoForm)
+}
+
+// GetComponentAppError is used to retrieve app error from the component struct.
+// It returns app error of the component.
+func (tttc *TagTopTrackComponent) GetComponentAppError() *utils.AppError {
+	return tttc.AppError
+}
+
+func (tttc *TagTopTrackComponent) SetComponentAppError(status int, err error) {
+	tttc.AppError = &utils.AppError{
+		Status: status,
+		Error:  err,
+	}
+}
+
+// Valid validates and sanitizes the tag top track form.
+func (f *TagTopTrackForm) Valid() error {
+	tag, err := validTag(f.Tag)
+	f.Tag = tag
+	if err != nil {
+		return err
+	}
+
+	if f.Cache, err = components.ResolveCacheMode(f.Cache, f.UseCache); err != nil {
+		return err
+	}
+	f.Lang, err = validLang(f.Lang)
+
+	return err
+}
+
+// Valid validates and sanitizes the tag info form.
+func (f *TagInfoForm) Valid() error {
+	tag, err := validTag(f.Tag)
+	f.Tag = tag
+
+	return err
+}
+
+// validTag normalizes the given tag name.
+// The tag is only sent as a query param, so it is not HTML escaped, else tags like "r&b" would not match.
+// It returns the normalized tag and error if the tag is empty.
+func validTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", errors.New("`tag` parameter is invalid")
+	}
+
+	return tag, nil
+}
+
+func init() {
+	components.ComponentMap["TagTopTrack"] = func(bc *components.BaseComponent) interface{} {
+		c := &TagTopTrackComponent{BaseComponent: *bc}
+
+		return TagTopTrack(c)
+	}
+}
@@ -0,0 +1,266 @@
+package track
+
+import (
+	"context"
+	"testing"
+
+	"geomelody/components"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagTopTrackForm_Valid(t *testing.T) {
+	type vars struct {
+		form *TagTopTrackForm
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   *TagTopTrackForm
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should fail when tag is empty",
+			vars: vars{
+				form: &TagTopTrackForm{
+					Tag: " ",
+				},
+			},
+			hasErr: true,
+			err:    "`tag` parameter is invalid",
+		},
+		{
+			name: "should success and normalize the tag",
+			vars: vars{
+				form: &TagTopTrackForm{
+					Tag: " Rock ",
+				},
+			},
+			want: &TagTopTrackForm{
+				Tag:   "rock",
+				Cache: components.CacheBypass,
+			},
+		},
+		{
+			name: "should success and keep the ampersand of the tag unescaped",
+			vars: vars{
+				form: &TagTopTrackForm{
+					Tag: " R&B ",
+				},
+			},
+			want: &TagTopTrackForm{
+				Tag:   "r&b",
+				Cache: components.CacheBypass,
+			},
+		},
+		{
+			name: "should success and map use_cache onto the default cache mode",
+			vars: vars{
+				form: &TagTopTrackForm{
+					Tag:      "rock",
+					UseCache: true,
+				},
+			},
+			want: &TagTopTrackForm{
+				Tag:      "rock",
+				Cache:    components.CacheDefault,
+				UseCache: true,
+			},
+		},
+		{
+			name: "should fail when cache mode is unknown",
+			vars: vars{
+				form: &TagTopTrackForm{
+					Tag:   "rock",
+					Cache: "always",
+				},
+			},
+			hasErr: true,
+			err:    "`cache` parameter is invalid",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+
+			// Run test
+			err := form.Valid()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Exactlyf(t, tCase.want, form, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestTagTopTrackComponent_GetTagTopTrack(t *testing.T) {
+	type vars struct {
+		component components.BaseComponent
+
+		form *TagTopTrackForm
+
+		headers map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to fetch the top track of the tag",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &TagTopTrackForm{
+					Tag: "rock",
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+		},
+		{
+			name: "should fail to fetch the top track of the tag",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &TagTopTrackForm{
+					Tag: "rock",
+				},
+				headers: map[string]string{
+					"x-mock-api": "error_response",
+				},
+			},
+			hasErr: true,
+			err:    "error",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+			tttc := &TagTopTrackComponent{
+				BaseComponent: tCase.vars.component,
+			}
+			ctx := tttc.ReqCtx
+			ctx = context.WithValue(ctx, "x-mock-headers", tCase.vars.headers)
+			tttc.ReqCtx = ctx
+
+			// Run test
+			got, err := tttc.GetTagTopTrack(form)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Equalf(t, tagChart, got.Meta.Chart, "case: %v", tCase)
+				assert.Equalf(t, "rock", got.Meta.Tag, "case: %v", tCase)
+				assert.Equalf(t, 1, got.Track.Rank, "case: %v", tCase)
+				assert.Equalf(t, "Yellow", got.Track.Name, "case: %v", tCase)
+				assert.Equalf(t, 7296979, got.Track.ArtistsInfo.Stats.Listeners, "case: %v", tCase)
+				assert.Lenf(t, got.TrackSuggestion, 5, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestTagTopTrackComponent_GetTagInfo(t *testing.T) {
+	type vars struct {
+		component components.BaseComponent
+
+		form *TagInfoForm
+
+		headers map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   *TagInfoResponse
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to fetch the tag info",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &TagInfoForm{
+					Tag: "rock",
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			want: &TagInfoResponse{
+				Name:    "rock",
+				Total:   4021019,
+				Reach:   401839,
+				Summary: "Rock music is a broad genre of popular music that originated as \"rock and roll\" in the United States in the late 1940s and early 1950s.. <a href=\"http://www.last.fm/tag/rock\">Read more on Last.fm</a>.",
+				Content: "Rock music is a broad genre of popular music that originated as \"rock and roll\" in the United States in the late 1940s and early 1950s, developing into a range of different styles in the mid-1960s and later.",
+			},
+		},
+		{
+			name: "should fail when tag is empty",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &TagInfoForm{},
+			},
+			hasErr: true,
+			err:    "`tag` parameter is invalid",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+			tttc := &TagTopTrackComponent{
+				BaseComponent: tCase.vars.component,
+			}
+			ctx := tttc.ReqCtx
+			ctx = context.WithValue(ctx, "x-mock-headers", tCase.vars.headers)
+			tttc.ReqCtx = ctx
+
+			// Run test
+			got, err := tttc.GetTagInfo(form)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Exactlyf(t, tCase.want, got, "case: %v", tCase)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package track
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"geomelody/components"
+	"geomelody/utils"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	defaultChartLimit = 10
+	maxChartLimit     = 100
+	defaultChartPage  = 1
+)
+
+type TopChartComponent struct {
+	components.BaseComponent
+}
+
+type TopChart interface {
+	GetRegionalTopChart(*RegionalTopChartForm) (*RegionalTopChartResponse, error)
+	GetRegionalTopChartForm() *RegionalTopChartForm
+	GetComponentAppError() *utils.AppError
+	SetComponentAppError(int, error)
+}
+
+type RegionalTopChartForm struct {
+	Country  string `json:"country"`
+	Limit    int    `json:"limit"`
+	Page     int    `json:"page"`
+	UseCache bool   `json:"use_cache"`
+}
+
+type ChartTrack struct {
+	Rank      int    `json:"rank"`
+	Name      string `json:"name"`
+	Duration  string `json:"duration"`
+	Listeners int    `json:"listeners"`
+	URL       string `json:"url"`
+
+	ArtistsInfo struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"artists_info"`
+}
+
+type RegionalTopChartResponse struct {
+	Meta struct {
+		Country    string `json:"country"`
+		Page       int    `json:"page"`
+		PerPage    int    `json:"perPage"`
+		TotalPages int    `json:"totalPages"`
+		Total      int    `json:"total"`
+	} `json:"meta"`
+
+	Tracks []ChartTrack `json:"tracks"`
+}
+
+// GetRegionalTopChart is used to fetch the top N tracks of the given country along with the paging details.
+// It returns top chart data and error.
+func (tcc *TopChartComponent) GetRegionalTopChart(form *RegionalTopChartForm) (*RegionalTopChartResponse, error) {
+	resp := new(RegionalTopChartResponse)
+	var err error
+	var data *LastFMTracksPayload
+	if err = form.Valid(); err != nil {
+		tcc.SetComponentAppError(http.StatusBadRequest, err)
+
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("chart:%v:%v:%v", form.Country, form.Limit, form.Page)
+	if components.IsRespInCache(form.UseCache, cacheKey, tcc.Cache, resp) {
+		return resp, nil
+	}
+
+	if data, err = fetchRegionalTopTrackData(tcc.ReqCtx, form.Country, strconv.Itoa(form.Limit), strconv.Itoa(form.Page)); err != nil {
+		tcc.SetComponentAppError(utils.StatusForError(err), err)
+	} else if err = processRegionalChartData(data, resp); err != nil {
+		tcc.SetComponentAppError(utils.StatusForError(err), err)
+	} else {
+		components.CheckAndCacheResp(form.UseCache, cacheKey, tcc.Cache, resp)
+	}
+
+	return resp, err
+}
+
+func processRegionalChartData(data *LastFMTracksPayload, rtcr *RegionalTopChartResponse) error {
+	tempTracks := data.Tracks
+	if tempTracks == nil {
+		return errors.New("received empty track data from vendor API. Please check input params")
+	}
+
+	rtcr.Meta.Country = tempTracks.Attr.Country
+	rtcr.Meta.Page = int(tempTracks.Attr.Page)
+	rtcr.Meta.PerPage = int(tempTracks.Attr.PerPage)
+	rtcr.Meta.TotalPages = int(tempTracks.Attr.TotalPages)
+	rtcr.Meta.Total = int(tempTracks.Attr.Total)
+
+	rtcr.Tracks = make([]ChartTrack, 0, len(tempTracks.Track))
+	for _, track := range tempTracks.Track {
+		chartTrack := new(ChartTrack)
+
+		chartTrack.Name = track.Name
+		chartTrack.Duration = strconv.Itoa(int(track.Duration))
+		chartTrack.Listeners = track.Listeners.Int()
+		chartTrack.URL = track.URL
+		chartTrack.Rank = int(track.Attr.Rank) + 1
+		chartTrack.ArtistsInfo.Name = track.Artist.Name
+		chartTrack.ArtistsInfo.URL = track.Artist.URL
+
+		rtcr.Tracks = append(rtcr.Tracks, *chartTrack)
+	}
+
+	log.Printf("processed regional chart data")
+
+	return nil
+}
+
+// GetRegionalTopChartForm is used to create a new regional top chart form instance.
+// It returns regional top chart form instance.
+func (tcc *TopChartComponent) GetRegionalTopChartForm() *RegionalTopChartForm {
+	return new(RegionalTopChartForm)
+}
+
+// GetComponentAppError is used to retrieve app error from the component struct.
+// It returns app error of the component.
+func (tcc *TopChartComponent) GetComponentAppError() *utils.AppError {
+	return tcc.AppError
+}
+
+func (tcc *TopChartComponent) SetComponentAppError(status int, err error) {
+	tcc.AppError = &utils.AppError{
+		Status: status,
+		Error:  err,
+	}
+}
+
+// Valid validates and sanitizes the top regional chart form.
+func (f *RegionalTopChartForm) Valid() error {
+	country, errMsg, err := components.LookupCountry(f.Country)
+	if err != nil {
+		return err
+	} else if errMsg == "" {
+		f.Country = country
+	}
+
+	if f.Limit == 0 {
+		f.Limit = defaultChartLimit
+	} else if f.Limit < 0 || f.Limit > maxChartLimit {
+		if errMsg != "" {
+			errMsg += "\n"
+		}
+		errMsg += fmt.Sprintf("`limit` parameter is invalid, it should be between 1 and %v", maxChartLimit)
+	}
+
+	if f.Page == 0 {
+		f.Page = defaultChartPage
+	} else if f.Page < 0 {
+		if errMsg != "" {
+			errMsg += "\n"
+		}
+		errMsg += "`page` parameter is invalid"
+	}
+
+	p := bluemonday.UGCPolicy()
+	f.Country = p.Sanitize(f.Country)
+
+	if errMsg != "" {
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
+func init() {
+	components.ComponentMap["TopChart"] = func(bc *components.BaseComponent) interface{} {
+		c := &TopChartComponent{BaseComponent: *bc}
+
+		return TopChart(c)
+	}
+}
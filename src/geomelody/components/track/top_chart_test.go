@@ -0,0 +1,179 @@
+package track
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"geomelody/components"
+	"geomelody/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionalTopChartForm_Valid(t *testing.T) {
+	constants.COUNTRIES_JSON_FILE_NAME = "../../countries.json"
+
+	type vars struct {
+		form *RegionalTopChartForm
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   *RegionalTopChartForm
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should fail when country is empty",
+			vars: vars{
+				form: &RegionalTopChartForm{
+					Country: "",
+				},
+			},
+			hasErr: true,
+			err:    "`country` parameter is invalid",
+		},
+		{
+			name: "should fail when limit is out of range",
+			vars: vars{
+				form: &RegionalTopChartForm{
+					Country: "in",
+					Limit:   500,
+				},
+			},
+			hasErr: true,
+			err:    "`limit` parameter is invalid",
+		},
+		{
+			name: "should fail when page is negative",
+			vars: vars{
+				form: &RegionalTopChartForm{
+					Country: "in",
+					Page:    -1,
+				},
+			},
+			hasErr: true,
+			err:    "`page` parameter is invalid",
+		},
+		{
+			name: "should success and apply default limit and page",
+			vars: vars{
+				form: &RegionalTopChartForm{
+					Country: "in",
+				},
+			},
+			want: &RegionalTopChartForm{
+				Country: "india",
+				Limit:   defaultChartLimit,
+				Page:    defaultChartPage,
+			},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+
+			// Run test
+			err := form.Valid()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Exactlyf(t, tCase.want, form, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestTopChartComponent_GetRegionalTopChart(t *testing.T) {
+	constants.COUNTRIES_JSON_FILE_NAME = "../../countries.json"
+
+	type vars struct {
+		component components.BaseComponent
+
+		form *RegionalTopChartForm
+
+		headers map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   string
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to fetch the top chart of the region",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopChartForm{
+					Country: "in",
+					Limit:   1,
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			want: `{ "meta": { "country": "India", "page": 1, "perPage": 1, "totalPages": 3499191, "total": 3499191 }, "tracks": [ { "rank": 1, "name": "Yellow", "duration": "267", "listeners": 2531979, "url": "https://www.last.fm/music/Coldplay/_/Yellow", "artists_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay" } } ] }`,
+		},
+		{
+			name: "should fail to fetch the top chart of the region",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopChartForm{
+					Country: "in",
+				},
+				headers: map[string]string{
+					"x-mock-api": "error_response",
+				},
+			},
+			hasErr: true,
+			err:    "error",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+			tcc := &TopChartComponent{
+				BaseComponent: tCase.vars.component,
+			}
+			ctx := tcc.ReqCtx
+			ctx = context.WithValue(ctx, "x-mock-headers", tCase.vars.headers)
+			tcc.ReqCtx = ctx
+
+			// Run test
+			got, err := tcc.GetRegionalTopChart(form)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				tempWant := new(RegionalTopChartResponse)
+				_ = json.Unmarshal([]byte(tCase.want), tempWant)
+				assert.Equal(t, tempWant, got, "case: %v", tCase)
+			}
+		})
+	}
+}
@@ -2,38 +2,64 @@ package track
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
-	"strings"
+	"sync"
 
 	"geomelody/components"
+	"geomelody/components/artist"
 	"geomelody/constants"
 	"geomelody/utils"
 
-	"github.com/gomodule/redigo/redis"
 	"github.com/microcosm-cc/bluemonday"
 )
 
 type TopTrackComponent struct {
 	components.BaseComponent
+
+	// Providers are the sources of the top track, the configured ones are used when it is nil.
+	Providers *Providers
 }
 
 type TopTrack interface {
 	GetRegionalTopTrack(*RegionalTopTrackForm) (*RegionalTopTrackResponse, error)
 	GetRegionalTopTrackForm() *RegionalTopTrackForm
+	GetRegionalTopTrackBatch(*RegionalTopTrackBatchForm) (*RegionalTopTrackBatchResponse, error)
+	GetRegionalTopTrackBatchForm() *RegionalTopTrackBatchForm
+	GetGlobalTopTrack(*GlobalTopTrackForm) (*RegionalTopTrackResponse, error)
+	GetGlobalTopTrackForm() *GlobalTopTrackForm
 	GetComponentAppError() *utils.AppError
 	SetComponentAppError(int, error)
 }
 
+const (
+	geoChart    = "geo"
+	globalChart = "global"
+	tagChart    = "tag"
+)
+
 type RegionalTopTrackForm struct {
-	Country  string `json:"country"`
-	UseCache bool   `json:"use_cache"`
+	Country    string               `json:"country"`
+	FlatLyrics bool                 `json:"flat_lyrics"`
+	Cache      components.CacheMode `json:"cache"`
+	// Lang is the ISO 639-1 language the names and lyrics of the track are translated in when the lyrics provider has them.
+	// Without it, only the track name is translated in English.
+	Lang string `json:"lang"`
+	// UseCache is the legacy switch between the default and bypass cache modes, used when Cache is unset.
+	UseCache bool `json:"use_cache"`
+}
+
+type GlobalTopTrackForm struct {
+	FlatLyrics bool                 `json:"flat_lyrics"`
+	Cache      components.CacheMode `json:"cache"`
+	// Lang is the ISO 639-1 language the names and lyrics of the track are translated in when the lyrics provider has them.
+	// Without it, only the track name is translated in English.
+	Lang string `json:"lang"`
+	// UseCache is the legacy switch between the default and bypass cache modes, used when Cache is unset.
+	UseCache bool `json:"use_cache"`
 }
 
 type TrackSuggestion struct {
@@ -49,9 +75,20 @@ type TrackSuggestion struct {
 	} `json:"artist_info"`
 }
 
+// EnrichmentStatus reports the outcome of an enrichment step of the top track.
+// A failing step does not fail the top track, the client renders the sections which are available.
+type EnrichmentStatus struct {
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
 type RegionalTopTrackResponse struct {
 	Meta struct {
-		Country string `json:"country"`
+		Chart   string `json:"chart"`
+		Country string `json:"country,omitempty"`
+		Tag     string `json:"tag,omitempty"`
+		// Cache is only set when the top track is served from cache.
+		Cache *CacheStatus `json:"cache,omitempty"`
 	} `json:"meta"`
 
 	Track struct {
@@ -61,43 +98,29 @@ type RegionalTopTrackResponse struct {
 		Listeners int    `json:"listeners"`
 		URL       string `json:"url"`
 
-		ArtistsInfo struct {
-			Name    string        `json:"name"`
-			URL     string        `json:"url"`
-			Images  []interface{} `json:"images"`
-			Summary string        `json:"summary"`
-			Stats   struct {
-				Listeners int `json:"listeners"`
-				PlayCount int `json:"play_count"`
-			}
-		} `json:"artists_info"`
+		ArtistsInfo artist.ArtistInfo `json:"artists_info"`
+
+		LyricsText
 
-		Lyrics string `json:"lyrics"`
+		// Translation is only set when a language is asked for.
+		Translation *LyricsTranslation `json:"translation,omitempty"`
 	} `json:"track"`
 
 	TrackSuggestion []TrackSuggestion
-}
 
-var countriesMap map[string]string
-
-type MusicMixSearchResponse struct {
-	HasTranslation bool   `json:"has_translation"`
-	HasLyrics      bool   `json:"has_lyrics"`
-	TrackID        int    `json:"track_id"`
-	CommonTrackID  int    `json:"commontrack_id"`
-	TrackName      string `json:"track_name"`
-	ArtistID       int    `json:"artist_id"`
-	ArtistName     string `json:"artist_name"`
-	AlbumID        int    `json:"album_id"`
-	AlbumName      string `json:"album_name"`
+	Enrichment struct {
+		ArtistInfo  EnrichmentStatus `json:"artist_info"`
+		Lyrics      EnrichmentStatus `json:"lyrics"`
+		Suggestions EnrichmentStatus `json:"suggestions"`
+	} `json:"enrichment"`
 }
 
 // GetRegionalTopTrack is used to call required external APIs to fetch top track, artists info of the track, lyrics of the track, and suggestions based on the artists and track of the given country.
+// The top track is returned even if some of the enrichment steps fail, their status being reported in the enrichment section.
 // It returns top track data and error.
 func (ttc *TopTrackComponent) GetRegionalTopTrack(form *RegionalTopTrackForm) (*RegionalTopTrackResponse, error) {
 	resp := new(RegionalTopTrackResponse)
 	var err error
-	var data utils.Data
 	if err = form.Valid(); err != nil {
 		ttc.AppError = &utils.AppError{
 			Status: http.StatusBadRequest,
@@ -107,353 +130,232 @@ func (ttc *TopTrackComponent) GetRegionalTopTrack(form *RegionalTopTrackForm) (*
 		return nil, err
 	}
 
-	if isRespInCache(form, ttc.RedisConn, resp) {
-		return resp, nil
-	}
+	providers := ttc.Providers.orConfigured()
+	fetch := func(ctx context.Context, rttr *RegionalTopTrackResponse) error {
+		rttr.Meta.Chart = geoChart
+		if err := providers.Chart.RegionalTopTrack(ctx, form.Country, rttr); err != nil {
+			return err
+		}
+		enrichTopTrack(ctx, providers, form.Lang, rttr)
 
-	if data, err = fetchRegionalTopTrackData(ttc.ReqCtx, form.Country); err != nil {
-		ttc.SetComponentAppError(http.StatusInternalServerError, err)
-	} else if err = processRegionalTrackData(data, resp); err != nil {
-		ttc.SetComponentAppError(http.StatusInternalServerError, err)
-	} else if data, err = fetchArtistInfo(ttc.ReqCtx, resp.Track.ArtistsInfo.Name); err != nil {
-		ttc.SetComponentAppError(http.StatusInternalServerError, err)
-	} else if err = processArtistInfo(data, resp); err != nil {
-		ttc.SetComponentAppError(http.StatusInternalServerError, err)
-	} else if err = fetchTrackLyrics(ttc.ReqCtx, resp); err != nil {
-		ttc.SetComponentAppError(http.StatusInternalServerError, err)
-	} else if data, err = fetchTrackSuggestions(ttc.ReqCtx, resp.Track.Name, resp.Track.ArtistsInfo.Name); err != nil {
-		ttc.SetComponentAppError(http.StatusInternalServerError, err)
-	} else if err = processTrackSuggestionsData(data, resp); err != nil {
-		ttc.SetComponentAppError(http.StatusInternalServerError, err)
-	} else {
-		checkAndCacheResp(form, ttc.RedisConn, resp)
+		return nil
 	}
 
-	return resp, err
-}
-
-func isRespInCache(form *RegionalTopTrackForm, redisConn redis.Conn, resp *RegionalTopTrackResponse) bool {
-	if form.UseCache {
-		dataStr, err := utils.GetData(redisConn, form.Country)
-		if err != nil {
-			log.Printf("data not found in cache")
-		} else if dataBytes, err := base64.StdEncoding.DecodeString(dataStr); err != nil {
-			log.Printf("error while decoding base64 cache data")
-		} else if err := json.Unmarshal(dataBytes, resp); err != nil {
-			log.Printf("error unmarshaling cache data")
-		} else {
-			log.Printf("data found in cache")
-			return true
-		}
-	}
+	cacheKey := topTrackCacheKey(form.Country, form.Lang)
+	if serveCachedTopTrack(ttc.ReqCtx, form.Cache, cacheKey, ttc.Cache, resp, fetch) {
+		resp.formatLyrics(form.FlatLyrics)
 
-	return false
-}
+		return resp, nil
+	} else if form.Cache == components.CacheOnlyIfCached {
+		ttc.SetComponentAppError(http.StatusGatewayTimeout, components.ErrNotCached)
 
-func checkAndCacheResp(form *RegionalTopTrackForm, redisConn redis.Conn, resp *RegionalTopTrackResponse) {
-	if form.UseCache {
-		if respBytes, err := json.Marshal(resp); err != nil {
-			log.Printf("error marshaling data to store in cache")
-		} else if respStr := base64.StdEncoding.EncodeToString(respBytes); respStr != "" {
-			ttl, _ := strconv.Atoi(constants.REDIS_DEFAULT_EXPIRY)
-			if status, err := utils.SetData(redisConn, form.Country, respStr, ttl); err != nil || !status {
-				log.Printf("error setting data in cache")
-			} else {
-				log.Printf("data succesfully stored in cache")
-			}
-		}
+		return nil, components.ErrNotCached
 	}
-}
 
-// fetchRegionalTopTrackData is used to fetch top track of the given country from LAST API.
-// It returns API response and error.
-func fetchRegionalTopTrackData(reqCtx context.Context, country string) (utils.Data, error) {
-	url := fmt.Sprintf("%v", constants.LAST_API_URL)
-	reqHeaders := map[string]string{"Content-Type": "application/json"}
-	params := map[string]string{
-		"method":  "geo.gettoptracks",
-		"country": country,
-		"api_key": constants.LAST_API_KEY,
-		"format":  "json",
-		"limit":   "1",
-	}
-	var data interface{}
-	var err error
-	if data, err = utils.GetAPIResponse(reqCtx, "GetTopTrackByCountry", url, http.MethodGet, nil, params, reqHeaders); err != nil {
-		return nil, err
+	if err = fetch(ttc.ReqCtx, resp); err != nil {
+		ttc.SetComponentAppError(utils.StatusForError(err), err)
+	} else {
+		cacheTopTrack(form.Cache, cacheKey, ttc.Cache, resp)
+		resp.formatLyrics(form.FlatLyrics)
 	}
-	caMap, _ := data.(map[string]interface{})
-
-	log.Printf("fetched regional track data")
 
-	return caMap, nil
+	return resp, err
 }
 
-func processRegionalTrackData(data utils.Data, rttr *RegionalTopTrackResponse) error {
-	if tr, ok := data["tracks"]; ok {
-		if tempTracks, ok := tr.(map[string]interface{}); ok {
-			if attr, ok := tempTracks["@attr"].(map[string]interface{}); ok {
-				rttr.Meta.Country, _ = attr["country"].(string)
-			}
-
-			tracks := tempTracks["track"].([]interface{})
-
-			if len(tracks) > 0 {
-				track, ok := tracks[0].(map[string]interface{})
-				if !ok {
-					return errors.New("error while processing track vendor API data")
-				}
-				rttr.Track.Name, _ = track["name"].(string)
-				rttr.Track.Duration, _ = track["duration"].(string)
-
-				tempListeners, _ := track["listeners"].(string)
-				listeners, _ := strconv.Atoi(tempListeners)
-				rttr.Track.Listeners = listeners
-
-				rttr.Track.URL, _ = track["url"].(string)
+// GetGlobalTopTrack is used to call required external APIs to fetch the worldwide top track along with the artists info, lyrics, and suggestions of the track.
+// It returns top track data and error.
+func (ttc *TopTrackComponent) GetGlobalTopTrack(form *GlobalTopTrackForm) (*RegionalTopTrackResponse, error) {
+	resp := new(RegionalTopTrackResponse)
+	var err error
+	if err = form.Valid(); err != nil {
+		ttc.SetComponentAppError(http.StatusBadRequest, err)
 
-				tempRank, _ := track["@attr"].(map[string]interface{})["rank"].(string)
-				rank, _ := strconv.Atoi(tempRank)
-				rttr.Track.Rank = rank + 1
+		return nil, err
+	}
 
-				if artist, ok := track["artist"].(map[string]interface{}); ok {
-					rttr.Track.ArtistsInfo.Name, _ = artist["name"].(string)
-					rttr.Track.ArtistsInfo.URL, _ = artist["url"].(string)
-				}
-			} else {
-				return errors.New("received empty track data from vendor API. Please check input params")
-			}
-		} else {
-			return errors.New("error while processing track vendor API data")
+	providers := ttc.Providers.orConfigured()
+	fetch := func(ctx context.Context, rttr *RegionalTopTrackResponse) error {
+		rttr.Meta.Chart = globalChart
+		if err := providers.Chart.GlobalTopTrack(ctx, rttr); err != nil {
+			return err
 		}
-
-		log.Printf("processed regional track data")
+		enrichTopTrack(ctx, providers, form.Lang, rttr)
 
 		return nil
 	}
 
-	return errors.New("received empty track data from vendor API. Please check input params")
-}
+	cacheKey := topTrackCacheKey(globalChart, form.Lang)
+	if serveCachedTopTrack(ttc.ReqCtx, form.Cache, cacheKey, ttc.Cache, resp, fetch) {
+		resp.formatLyrics(form.FlatLyrics)
 
-func fetchArtistInfo(reqCtx context.Context, artist string) (utils.Data, error) {
-	url := fmt.Sprintf("%v", constants.LAST_API_URL)
-	reqHeaders := map[string]string{"Content-Type": "application/json"}
-	params := map[string]string{
-		"method":  "artist.getinfo",
-		"artist":  artist,
-		"api_key": constants.LAST_API_KEY,
-		"format":  "json",
-		"limit":   "1",
-	}
-	var data interface{}
-	var err error
-	if data, err = utils.GetAPIResponse(reqCtx, "GetArtistInfo", url, http.MethodGet, nil, params, reqHeaders); err != nil {
-		return nil, err
+		return resp, nil
+	} else if form.Cache == components.CacheOnlyIfCached {
+		ttc.SetComponentAppError(http.StatusGatewayTimeout, components.ErrNotCached)
+
+		return nil, components.ErrNotCached
 	}
-	caMap, _ := data.(map[string]interface{})
 
-	log.Printf("fetched artist data")
+	if err = fetch(ttc.ReqCtx, resp); err != nil {
+		ttc.SetComponentAppError(utils.StatusForError(err), err)
+	} else {
+		cacheTopTrack(form.Cache, cacheKey, ttc.Cache, resp)
+		resp.formatLyrics(form.FlatLyrics)
+	}
 
-	return caMap, nil
+	return resp, err
 }
 
-func processArtistInfo(data utils.Data, rttr *RegionalTopTrackResponse) error {
-	if artist, ok := data["artist"]; ok {
-		if ar, ok := artist.(map[string]interface{}); ok {
-			rttr.Track.ArtistsInfo.Images, _ = ar["image"].([]interface{})
-
-			stats, ok := ar["stats"].(map[string]interface{})
-			if !ok {
-				return errors.New("error while processing artist vendor API data")
-			}
-
-			tempPlayCount, _ := stats["playcount"].(string)
-			playCount, _ := strconv.Atoi(tempPlayCount)
-			rttr.Track.ArtistsInfo.Stats.PlayCount = playCount
-
-			tempListeners, _ := stats["listeners"].(string)
-			listeners, _ := strconv.Atoi(tempListeners)
-			rttr.Track.ArtistsInfo.Stats.Listeners = listeners
-
-			bio, ok := ar["bio"].(map[string]interface{})
-			if !ok {
-				return errors.New("error while processing artist vendor API data")
-			}
-
-			tempSummary, _ := bio["summary"].(string)
-
-			summary := strings.Replace(tempSummary, "\n", ". ", -1)
-			rttr.Track.ArtistsInfo.Summary = summary
-
-			log.Printf("processed artist data")
-		} else {
-			return errors.New("error while processing artist vendor API data")
+// formatLyrics is used to drop the flat lyrics string from the response unless the client asked for it.
+// The cached response always keeps it, so it is called only after caching.
+func (rttr *RegionalTopTrackResponse) formatLyrics(flat bool) {
+	if !flat {
+		rttr.Track.Lyrics = ""
+		if rttr.Track.Translation != nil {
+			rttr.Track.Translation.Lyrics = ""
 		}
-	} else {
-		log.Printf("received empty artist data")
 	}
-
-	return nil
 }
 
-func fetchTrackLyrics(reqCtx context.Context, rttr *RegionalTopTrackResponse) error {
-	var data utils.Data
-	var err error
-	musicMixResp := new(MusicMixSearchResponse)
-	if data, err = fetchTrackID(reqCtx, rttr.Track.ArtistsInfo.Name, rttr.Track.Name); err != nil {
+// enrichTopTrack is used to call the providers which add artists info, lyrics, and suggestions to the already fetched top track.
+// The steps only depend on the track and artist names, so they run concurrently and every step writes its own section
+// of the response. The translated names found by the lyrics step are applied once all the steps are done, and the
+// translated lyrics are added next to the original ones when a language is asked for.
+// The outcome of every step is reported in the enrichment section of the response, hence it never fails the top track.
+func enrichTopTrack(reqCtx context.Context, providers Providers, lang string, resp *RegionalTopTrackResponse) {
+	trackName, artistName := resp.Track.Name, resp.Track.ArtistsInfo.Name
+	var translated *LyricsTranslation
+	var wg sync.WaitGroup
+
+	runEnrichmentStep(&wg, &resp.Enrichment.ArtistInfo, func() error {
+		return providers.Artist.ArtistInfo(reqCtx, artistName, &resp.Track.ArtistsInfo)
+	})
+	runEnrichmentStep(&wg, &resp.Enrichment.Lyrics, func() (err error) {
+		translated, err = providers.Lyrics.TrackLyrics(reqCtx, trackName, artistName, lang, &resp.Track.LyricsText)
 		return err
-	} else if err = processTrackIDData(data, musicMixResp); err != nil {
+	})
+	runEnrichmentStep(&wg, &resp.Enrichment.Suggestions, func() (err error) {
+		resp.TrackSuggestion, err = providers.Similarity.SimilarTracks(reqCtx, trackName, artistName)
 		return err
-	}
-
-	if musicMixResp.HasLyrics {
-		if data, err = fetchLyrics(reqCtx, strconv.Itoa(musicMixResp.TrackID), strconv.Itoa(musicMixResp.CommonTrackID)); err != nil {
-			return err
-		} else if err = processLyricsData(data, rttr); err != nil {
-			return err
+	})
+	wg.Wait()
+
+	if translated != nil {
+		resp.Track.Name = translated.TrackName
+		resp.Track.ArtistsInfo.Name = translated.ArtistName
+		if lang != "" {
+			resp.Track.Translation = translated
 		}
 	}
-
-	if musicMixResp.HasTranslation {
-		rttr.Track.Name = musicMixResp.TrackName
-		rttr.Track.ArtistsInfo.Name = musicMixResp.ArtistName
-	}
-
-	return nil
 }
 
-func fetchTrackID(reqCtx context.Context, artist, track string) (utils.Data, error) {
-	url := fmt.Sprintf("%vtrack.search", constants.MUSIC_MIX_URL)
-	reqHeaders := map[string]string{"Content-Type": "application/json"}
-	params := map[string]string{
-		"q_track":   track,
-		"q_artist":  artist,
-		"apikey":    constants.MUSIC_MIX_API_KEY,
-		"page_size": "1",
-	}
-	var data interface{}
-	var err error
-	if data, err = utils.GetAPIResponse(reqCtx, "GetTrackID", url, http.MethodGet, nil, params, reqHeaders); err != nil {
-		return nil, err
-	}
-	caMap, _ := data.(map[string]interface{})
-
-	log.Printf("fetched track ID data")
-
-	return caMap, nil
-}
-
-func processTrackIDData(data utils.Data, mms *MusicMixSearchResponse) error {
-	if out, ok := data["message"].(map[string]interface{}); ok {
-		trackList := out["body"].(map[string]interface{})["track_list"].([]interface{})
-
-		if len(trackList) == 1 {
-			track := trackList[0].(map[string]interface{})["track"].(map[string]interface{})
-			hasLyrics := int(track["has_lyrics"].(float64))
-			if hasLyrics == 0 {
-				mms.HasLyrics = false
-				log.Printf("lyrics not present for the track")
-				return nil
-			} else {
-				mms.HasLyrics = true
+// runEnrichmentStep is used to run an enrichment step in its own goroutine and report its outcome in the given status.
+// A panic of the step is reported as its error, as it would otherwise bring down the whole server.
+func runEnrichmentStep(wg *sync.WaitGroup, status *EnrichmentStatus, step func() error) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				*status = enrichmentStatus(fmt.Errorf("enrichment step panicked: %v", r))
 			}
+		}()
 
-			trackID, _ := track["track_id"].(float64)
-			mms.TrackID = int(trackID)
-
-			commonTrackId, _ := track["commontrack_id"].(float64)
-			mms.CommonTrackID = int(commonTrackId)
-
-			artistID, _ := track["artist_id"].(float64)
-			mms.ArtistID = int(artistID)
-
-			albumID, _ := track["album_id"].(float64)
-			mms.AlbumID = int(albumID)
-
-			mms.AlbumName, _ = track["album_name"].(string)
+		*status = enrichmentStatus(step())
+	}()
+}
 
-			checkForTranslation(track, mms)
+// enrichmentStatus is used to build the status of an enrichment step from its error.
+// It returns enrichment status.
+func enrichmentStatus(err error) EnrichmentStatus {
+	if err != nil {
+		log.Printf("error enriching the top track: %v", err)
 
-			log.Printf("processed track ID data")
-		} else {
-			log.Printf("received more than one track ID data, hence not processed")
+		return EnrichmentStatus{
+			Status: utils.StatusForError(err),
+			Error:  err.Error(),
 		}
-	} else {
-		return errors.New("error while processing track ID vendor API data")
 	}
 
-	return nil
+	return EnrichmentStatus{Status: http.StatusOK}
 }
 
-func checkForTranslation(track map[string]interface{}, mms *MusicMixSearchResponse) {
-	if hasTranslation, ok := track["track_name_translation_list"]; ok {
-		translations, _ := hasTranslation.([]interface{})
-		for _, translation := range translations {
-			val, ok := translation.(map[string]interface{})
-			if !ok {
-				return
-			}
-
-			transVal, ok := val["track_name_translation"].(map[string]interface{})
-			if !ok {
-				return
-			}
+// isEnriched is used to check whether every enrichment step of the top track succeeded.
+// Partial responses are not cached, so that the failed steps are retried by the next request.
+// It returns true if the top track is fully enriched.
+func (rttr *RegionalTopTrackResponse) isEnriched() bool {
+	return rttr.Enrichment.ArtistInfo.Status == http.StatusOK &&
+		rttr.Enrichment.Lyrics.Status == http.StatusOK &&
+		rttr.Enrichment.Suggestions.Status == http.StatusOK
+}
 
-			if transVal["language"].(string) == "EN" {
-				mms.HasTranslation = true
-				mms.TrackName = transVal["translation"].(string)
-				mms.ArtistName = track["artist_name"].(string)
-				log.Printf("found and processed translated data")
-				break
-			}
-		}
+// fetchRegionalTopTrackData is used to fetch a page of top tracks of the given country from LAST API.
+// It returns API response and error.
+func fetchRegionalTopTrackData(reqCtx context.Context, country, limit, page string) (*LastFMTracksPayload, error) {
+	url := fmt.Sprintf("%v", constants.LAST_API_URL)
+	reqHeaders := map[string]string{"Content-Type": "application/json"}
+	params := map[string]string{
+		"method":  "geo.gettoptracks",
+		"country": country,
+		"api_key": constants.LAST_API_KEY,
+		"format":  "json",
+		"limit":   limit,
+		"page":    page,
 	}
+	data := new(LastFMTracksPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTopTrackByCountry", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
+		return nil, err
+	}
+
+	log.Printf("fetched regional track data")
+
+	return data, nil
 }
 
-func fetchLyrics(reqCtx context.Context, trackID, commonTrackID string) (utils.Data, error) {
-	url := fmt.Sprintf("%vtrack.lyrics.get", constants.MUSIC_MIX_URL)
+// fetchGlobalTopTrackData is used to fetch a page of the worldwide top tracks from LAST API.
+// It returns API response and error.
+func fetchGlobalTopTrackData(reqCtx context.Context, limit, page string) (*LastFMTracksPayload, error) {
+	url := fmt.Sprintf("%v", constants.LAST_API_URL)
 	reqHeaders := map[string]string{"Content-Type": "application/json"}
 	params := map[string]string{
-		"track_id":       trackID,
-		"commontrack_id": commonTrackID,
-		"apikey":         constants.MUSIC_MIX_API_KEY,
-		"page_size":      "1",
+		"method":  "chart.gettoptracks",
+		"api_key": constants.LAST_API_KEY,
+		"format":  "json",
+		"limit":   limit,
+		"page":    page,
 	}
-	var data interface{}
-	var err error
-	if data, err = utils.GetAPIResponse(reqCtx, "GetTrackLyrics", url, http.MethodGet, nil, params, reqHeaders); err != nil {
+	data := new(LastFMTracksPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetGlobalTopTracks", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
 		return nil, err
 	}
-	caMap, _ := data.(map[string]interface{})
 
-	log.Printf("fetched lyrics data")
+	log.Printf("fetched global track data")
 
-	return caMap, nil
+	return data, nil
 }
 
-func processLyricsData(data utils.Data, rttr *RegionalTopTrackResponse) error {
-	if out, ok := data["message"].(map[string]interface{}); ok {
-		if body, _ := out["body"].(map[string]interface{}); ok {
-			lyrics, ok := body["lyrics"].(map[string]interface{})
-			if !ok {
-				return errors.New("error while processing lyrics vendor API data")
-			}
+// processRegionalTrackData is used to read the first track of a geo.gettoptracks or chart.gettoptracks API data.
+// It returns error.
+func processRegionalTrackData(data *LastFMTracksPayload, rttr *RegionalTopTrackResponse) error {
+	if data.Tracks == nil || len(data.Tracks.Track) == 0 {
+		return errors.New("received empty track data from vendor API. Please check input params")
+	}
+	rttr.Meta.Country = data.Tracks.Attr.Country
 
-			lyric, _ := lyrics["lyrics_body"].(string)
-			lyric = strings.Replace(lyric, "******* This Lyrics is NOT for Commercial use *******", "", -1)
-			lyric = strings.Replace(lyric, "\n", ". ", -1)
-			rttr.Track.Lyrics = lyric
+	track := data.Tracks.Track[0]
+	rttr.Track.Name = track.Name
+	rttr.Track.Duration = strconv.Itoa(int(track.Duration))
+	rttr.Track.Listeners = track.Listeners.Int()
+	rttr.Track.URL = track.URL
+	rttr.Track.Rank = int(track.Attr.Rank) + 1
+	rttr.Track.ArtistsInfo.Name = track.Artist.Name
+	rttr.Track.ArtistsInfo.URL = track.Artist.URL
 
-			log.Printf("processed lyrics data")
-		}
-	} else {
-		return errors.New("error while processing lyrics vendor API data")
-	}
+	log.Printf("processed regional track data")
 
 	return nil
 }
 
-func fetchTrackSuggestions(reqCtx context.Context, track, artist string) (utils.Data, error) {
+func fetchTrackSuggestions(reqCtx context.Context, track, artist string) (*LastFMSimilarTracksPayload, error) {
 	url := fmt.Sprintf("%v", constants.LAST_API_URL)
 	reqHeaders := map[string]string{"Content-Type": "application/json"}
 	params := map[string]string{
@@ -464,59 +366,46 @@ func fetchTrackSuggestions(reqCtx context.Context, track, artist string) (utils.
 		"format":  "json",
 		"limit":   "5",
 	}
-	var data interface{}
-	var err error
-	if data, err = utils.GetAPIResponse(reqCtx, "GetTrackSuggestions", url, http.MethodGet, nil, params, reqHeaders); err != nil {
+	data := new(LastFMSimilarTracksPayload)
+	if err := utils.GetAPIResponseAs(reqCtx, "GetTrackSuggestions", url, http.MethodGet, nil, params, reqHeaders, data); err != nil {
 		return nil, err
 	}
-	caMap, _ := data.(map[string]interface{})
 
 	log.Printf("fetched track suggestions data")
 
-	return caMap, nil
+	return data, nil
 }
 
-func processTrackSuggestionsData(data utils.Data, rttr *RegionalTopTrackResponse) error {
-	if st, ok := data["similartracks"]; ok {
-		if similarTracks, ok := st.(map[string]interface{}); ok {
-			tracks, _ := similarTracks["track"].([]interface{})
-			rttr.TrackSuggestion = make([]TrackSuggestion, 0)
-			if len(tracks) == 0 {
-				log.Printf("received empty track suggestions data")
+func processTrackSuggestionsData(data *LastFMSimilarTracksPayload) ([]TrackSuggestion, error) {
+	if data.SimilarTracks == nil {
+		log.Printf("received empty track suggestions data")
 
-				return nil
-			}
-			for _, track := range tracks {
-				val, ok := track.(map[string]interface{})
-				if !ok {
-					return errors.New("error while processing track vendor API data")
-				}
-				trackSuggestion := new(TrackSuggestion)
-
-				trackSuggestion.Name, _ = val["name"].(string)
-				trackSuggestion.URL, _ = val["url"].(string)
-				trackSuggestion.Match, _ = val["match"].(float64)
-				trackSuggestion.Duration, _ = val["duration"].(float64)
-
-				trackSuggestion.PlayCount, _ = val["playcount"].(float64)
-
-				if artist, ok := val["artist"].(map[string]interface{}); ok {
-					trackSuggestion.ArtistInfo.Name, _ = artist["name"].(string)
-					trackSuggestion.ArtistInfo.URL, _ = artist["url"].(string)
-				}
-
-				rttr.TrackSuggestion = append(rttr.TrackSuggestion, *trackSuggestion)
-			}
+		return nil, nil
+	}
 
-			log.Printf("processed track suggestions data")
-		} else {
-			return errors.New("error while processing track vendor API data")
-		}
-	} else {
+	suggestions := make([]TrackSuggestion, 0, len(data.SimilarTracks.Track))
+	if len(data.SimilarTracks.Track) == 0 {
 		log.Printf("received empty track suggestions data")
+
+		return suggestions, nil
+	}
+	for _, track := range data.SimilarTracks.Track {
+		trackSuggestion := new(TrackSuggestion)
+
+		trackSuggestion.Name = track.Name
+		trackSuggestion.URL = track.URL
+		trackSuggestion.Match = track.Match
+		trackSuggestion.Duration = float64(track.Duration)
+		trackSuggestion.PlayCount = float64(track.PlayCount)
+		trackSuggestion.ArtistInfo.Name = track.Artist.Name
+		trackSuggestion.ArtistInfo.URL = track.Artist.URL
+
+		suggestions = append(suggestions, *trackSuggestion)
 	}
 
-	return nil
+	log.Printf("processed track suggestions data")
+
+	return suggestions, nil
 }
 
 // GetRegionalTopTrackForm is used to create a new regional top track form instance.
@@ -525,6 +414,12 @@ func (ttc *TopTrackComponent) GetRegionalTopTrackForm() *RegionalTopTrackForm {
 	return new(RegionalTopTrackForm)
 }
 
+// GetGlobalTopTrackForm is used to create a new global top track form instance.
+// It returns global top track form instance.
+func (ttc *TopTrackComponent) GetGlobalTopTrackForm() *GlobalTopTrackForm {
+	return new(GlobalTopTrackForm)
+}
+
 // GetComponentAppError is used to retrieve app error from the component struct.
 // It returns app error of the component.
 func (ttc *TopTrackComponent) GetComponentAppError() *utils.AppError {
@@ -540,32 +435,25 @@ func (ttc *TopTrackComponent) SetComponentAppError(status int, err error) {
 
 // Valid validates and sanitizes the top regional track form.
 func (f *RegionalTopTrackForm) Valid() error {
-	errMsg := ""
-	if f.Country == "" {
-		errMsg += "`country` parameter is invalid"
-	} else {
-		countriesStr, err := os.ReadFile(constants.COUNTRIES_JSON_FILE_NAME)
-		if err != nil {
-			return err
-		}
-
-		err = json.Unmarshal(countriesStr, &countriesMap)
-		if err != nil {
-			return err
-		}
+	country, errMsg, err := components.LookupCountry(f.Country)
+	if err != nil {
+		return err
+	} else if errMsg == "" {
+		f.Country = country
+	}
 
-		if country, ok := countriesMap[strings.ToLower(f.Country)]; ok {
-			f.Country = country
-		} else {
-			errMsg += "`country` not found in our database. Please check the country input param, it should follow the ISO 3166-1-Alpha-2 code format"
+	if f.Cache, err = components.ResolveCacheMode(f.Cache, f.UseCache); err != nil {
+		if errMsg != "" {
+			errMsg += "\n"
 		}
+		errMsg += err.Error()
 	}
 
-	if f.UseCache != true && f.UseCache != false {
+	if f.Lang, err = validLang(f.Lang); err != nil {
 		if errMsg != "" {
 			errMsg += "\n"
 		}
-		errMsg += "`use_cache` parameter is invalid"
+		errMsg += err.Error()
 	}
 
 	p := bluemonday.UGCPolicy()
@@ -578,6 +466,17 @@ func (f *RegionalTopTrackForm) Valid() error {
 	return nil
 }
 
+// Valid validates the global top track form, it has no mandatory params.
+func (f *GlobalTopTrackForm) Valid() error {
+	var err error
+	if f.Cache, err = components.ResolveCacheMode(f.Cache, f.UseCache); err != nil {
+		return err
+	}
+	f.Lang, err = validLang(f.Lang)
+
+	return err
+}
+
 func init() {
 	components.ComponentMap["TopTrack"] = func(bc *components.BaseComponent) interface{} {
 		c := &TopTrackComponent{BaseComponent: *bc}
@@ -0,0 +1,212 @@
+package track
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"geomelody/components"
+	"geomelody/constants"
+	"geomelody/utils"
+)
+
+const (
+	defaultBatchWorkers   = 8
+	defaultBatchCountries = 250
+)
+
+type RegionalTopTrackBatchForm struct {
+	Countries  []string             `json:"countries"`
+	Workers    int                  `json:"workers"`
+	FlatLyrics bool                 `json:"flat_lyrics"`
+	Cache      components.CacheMode `json:"cache"`
+	// Lang is the ISO 639-1 language the names of the track are translated in when the lyrics provider has them, English by default.
+	Lang string `json:"lang"`
+	// UseCache is the legacy switch between the default and bypass cache modes, used when Cache is unset.
+	UseCache bool `json:"use_cache"`
+}
+
+type RegionalTopTrackBatchResult struct {
+	Country string                    `json:"country"`
+	Status  int                       `json:"status"`
+	Data    *RegionalTopTrackResponse `json:"data,omitempty"`
+	Error   string                    `json:"error,omitempty"`
+}
+
+type RegionalTopTrackBatchResponse struct {
+	Meta struct {
+		Total     int `json:"total"`
+		Succeeded int `json:"succeeded"`
+		Failed    int `json:"failed"`
+	} `json:"meta"`
+
+	Results []RegionalTopTrackBatchResult `json:"results"`
+}
+
+// GetRegionalTopTrackBatch is used to resolve the top track of every given country concurrently using a bounded pool of workers.
+// Failures are reported per country, hence it only returns an error when the batch itself is invalid.
+// It returns batch top track data and error.
+func (ttc *TopTrackComponent) GetRegionalTopTrackBatch(form *RegionalTopTrackBatchForm) (*RegionalTopTrackBatchResponse, error) {
+	if err := form.Valid(); err != nil {
+		ttc.SetComponentAppError(http.StatusBadRequest, err)
+
+		return nil, err
+	}
+
+	resp := new(RegionalTopTrackBatchResponse)
+	resp.Results = make([]RegionalTopTrackBatchResult, len(form.Countries))
+
+	jobs := make(chan int)
+	wg := new(sync.WaitGroup)
+	for w := 0; w < form.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ttc.runBatchWorker(form, jobs, resp.Results)
+		}()
+	}
+
+	for i := range form.Countries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, result := range resp.Results {
+		if result.Error == "" {
+			resp.Meta.Succeeded++
+		} else {
+			resp.Meta.Failed++
+		}
+	}
+	resp.Meta.Total = len(resp.Results)
+
+	log.Printf("processed batch of %v countries", resp.Meta.Total)
+
+	return resp, nil
+}
+
+// runBatchWorker resolves the countries received on the jobs channel and stores the outcome at the same index in results.
+// Every worker uses its own cache, since a redis connection is not safe for concurrent use.
+func (ttc *TopTrackComponent) runBatchWorker(form *RegionalTopTrackBatchForm, jobs <-chan int, results []RegionalTopTrackBatchResult) {
+	var cache utils.Cache
+	if form.Cache != components.CacheBypass {
+		cache = utils.NewCache()
+		defer func() {
+			_ = cache.Close()
+		}()
+	}
+
+	for i := range jobs {
+		country := form.Countries[i]
+		results[i] = RegionalTopTrackBatchResult{Country: country}
+
+		if err := ttc.ReqCtx.Err(); err != nil {
+			results[i].Status = http.StatusServiceUnavailable
+			results[i].Error = err.Error()
+			continue
+		}
+
+		c := &TopTrackComponent{
+			BaseComponent: components.BaseComponent{
+				ReqCtx:   ttc.ReqCtx,
+				AppError: new(utils.AppError),
+				Cache:    cache,
+			},
+			Providers: ttc.Providers,
+		}
+		countryForm := &RegionalTopTrackForm{
+			Country:    country,
+			FlatLyrics: form.FlatLyrics,
+			Cache:      form.Cache,
+			Lang:       form.Lang,
+		}
+
+		if d, err := c.GetRegionalTopTrack(countryForm); err != nil {
+			log.Printf("error resolving top track of %v: %v", country, err)
+			results[i].Status = c.GetComponentAppError().Status
+			results[i].Error = err.Error()
+		} else {
+			results[i].Status = http.StatusOK
+			results[i].Data = d
+		}
+	}
+}
+
+// GetRegionalTopTrackBatchForm is used to create a new regional top track batch form instance.
+// It returns regional top track batch form instance.
+func (ttc *TopTrackComponent) GetRegionalTopTrackBatchForm() *RegionalTopTrackBatchForm {
+	return new(RegionalTopTrackBatchForm)
+}
+
+// Valid validates the top regional track batch form, removes duplicate countries and applies the worker limits.
+func (f *RegionalTopTrackBatchForm) Valid() error {
+	errMsg := ""
+	maxCountries := envIntOrDefault(constants.BATCH_MAX_COUNTRIES, defaultBatchCountries)
+	maxWorkers := envIntOrDefault(constants.BATCH_MAX_WORKERS, defaultBatchWorkers)
+
+	countries := make([]string, 0, len(f.Countries))
+	seen := make(map[string]bool, len(f.Countries))
+	for _, country := range f.Countries {
+		country = strings.ToLower(strings.TrimSpace(country))
+		if !seen[country] {
+			seen[country] = true
+			countries = append(countries, country)
+		}
+	}
+	f.Countries = countries
+
+	if len(f.Countries) == 0 {
+		errMsg += "`countries` parameter is invalid"
+	} else if len(f.Countries) > maxCountries {
+		errMsg += fmt.Sprintf("`countries` parameter is invalid, at most %v countries are allowed", maxCountries)
+	}
+
+	if f.Workers == 0 || f.Workers > maxWorkers {
+		f.Workers = maxWorkers
+	} else if f.Workers < 0 {
+		if errMsg != "" {
+			errMsg += "\n"
+		}
+		errMsg += "`workers` parameter is invalid"
+	}
+
+	var err error
+	if f.Cache, err = components.ResolveCacheMode(f.Cache, f.UseCache); err != nil {
+		if errMsg != "" {
+			errMsg += "\n"
+		}
+		errMsg += err.Error()
+	}
+
+	if f.Lang, err = validLang(f.Lang); err != nil {
+		if errMsg != "" {
+			errMsg += "\n"
+		}
+		errMsg += err.Error()
+	}
+
+	if errMsg != "" {
+		return errors.New(errMsg)
+	}
+
+	if f.Workers > len(f.Countries) {
+		f.Workers = len(f.Countries)
+	}
+
+	return nil
+}
+
+// envIntOrDefault parses the given integer config value.
+// It returns the parsed value or the default if the value is missing or not a positive integer.
+func envIntOrDefault(val string, def int) int {
+	if i, err := strconv.Atoi(val); err == nil && i > 0 {
+		return i
+	}
+
+	return def
+}
@@ -0,0 +1,185 @@
+package track
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"geomelody/components"
+	"geomelody/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionalTopTrackBatchForm_Valid(t *testing.T) {
+	type vars struct {
+		form *RegionalTopTrackBatchForm
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   *RegionalTopTrackBatchForm
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should fail when countries are empty",
+			vars: vars{
+				form: &RegionalTopTrackBatchForm{},
+			},
+			hasErr: true,
+			err:    "`countries` parameter is invalid",
+		},
+		{
+			name: "should fail when workers is negative",
+			vars: vars{
+				form: &RegionalTopTrackBatchForm{
+					Countries: []string{"in"},
+					Workers:   -1,
+				},
+			},
+			hasErr: true,
+			err:    "`workers` parameter is invalid",
+		},
+		{
+			name: "should fail when cache mode is unknown",
+			vars: vars{
+				form: &RegionalTopTrackBatchForm{
+					Countries: []string{"in"},
+					Cache:     "later",
+				},
+			},
+			hasErr: true,
+			err:    "`cache` parameter is invalid",
+		},
+		{
+			name: "should success and remove duplicate countries and cap workers",
+			vars: vars{
+				form: &RegionalTopTrackBatchForm{
+					Countries: []string{"in", " IN", "us"},
+				},
+			},
+			want: &RegionalTopTrackBatchForm{
+				Countries: []string{"in", "us"},
+				Workers:   2,
+				Cache:     components.CacheBypass,
+			},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+
+			// Run test
+			err := form.Valid()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Exactlyf(t, tCase.want, form, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestTopTrackComponent_GetRegionalTopTrackBatch(t *testing.T) {
+	constants.COUNTRIES_JSON_FILE_NAME = "../../countries.json"
+
+	type vars struct {
+		component components.BaseComponent
+
+		form *RegionalTopTrackBatchForm
+
+		headers map[string]string
+	}
+
+	type result struct {
+		country string
+		status  int
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   []result
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success and report the failures per country",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopTrackBatchForm{
+					Countries: []string{"in", "xx", "us", "gb"},
+					Workers:   2,
+				},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			want: []result{
+				{country: "in", status: http.StatusOK},
+				{country: "xx", status: http.StatusBadRequest},
+				{country: "us", status: http.StatusOK},
+				{country: "gb", status: http.StatusOK},
+			},
+		},
+		{
+			name: "should fail when the batch is empty",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopTrackBatchForm{},
+			},
+			hasErr: true,
+			err:    "`countries` parameter is invalid",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+			ttc := &TopTrackComponent{
+				BaseComponent: tCase.vars.component,
+			}
+			ctx := ttc.ReqCtx
+			ctx = context.WithValue(ctx, "x-mock-headers", tCase.vars.headers)
+			ttc.ReqCtx = ctx
+
+			// Run test
+			got, err := ttc.GetRegionalTopTrackBatch(form)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				if assert.Lenf(t, got.Results, len(tCase.want), "case: %v", tCase) {
+					for i, want := range tCase.want {
+						assert.Equalf(t, want.country, got.Results[i].Country, "case: %v", tCase)
+						assert.Equalf(t, want.status, got.Results[i].Status, "case: %v", tCase)
+					}
+				}
+				assert.Equalf(t, 1, got.Meta.Failed, "case: %v", tCase)
+				assert.Equalf(t, 3, got.Meta.Succeeded, "case: %v", tCase)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package track
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"geomelody/components"
+	"geomelody/constants"
+	"geomelody/utils"
+)
+
+const (
+	defaultTopTrackSoftTTL = time.Hour
+	defaultTopTrackHardTTL = 24 * time.Hour
+
+	// revalidateTimeout bounds a background refresh, which outlives the request that started it.
+	revalidateTimeout = 30 * time.Second
+)
+
+// CacheStatus tells how old the cached top track served to the client is.
+type CacheStatus struct {
+	Age   int  `json:"age"`
+	Stale bool `json:"stale"`
+}
+
+// topTrackFetcher fetches and enriches a top track into the given response.
+type topTrackFetcher func(context.Context, *RegionalTopTrackResponse) error
+
+var (
+	revalidatingMu sync.Mutex
+	revalidating   = make(map[string]bool)
+
+	// newRevalidationCache opens the cache a background refresh stores the top track in, as the cache of the request
+	// that started it is closed once the request is done.
+	newRevalidationCache = utils.NewCache
+)
+
+// topTrackTTLs reads the soft and hard TTLs of the cached top tracks.
+// A top track is fresh until the soft TTL, then stale but still served while it is refreshed, and gone after the hard TTL.
+// The soft TTL defaults to REDIS_DEFAULT_EXPIRY, and the hard TTL is never shorter than the soft one.
+// It returns the soft and hard TTLs.
+func topTrackTTLs() (time.Duration, time.Duration) {
+	soft := defaultTopTrackSoftTTL
+	if expiry, err := strconv.Atoi(constants.REDIS_DEFAULT_EXPIRY); err == nil && expiry > 0 {
+		soft = time.Duration(expiry) * time.Second
+	}
+	soft = envDurationOrDefault(constants.TOP_TRACK_CACHE_SOFT_TTL, soft)
+	hard := envDurationOrDefault(constants.TOP_TRACK_CACHE_HARD_TTL, defaultTopTrackHardTTL)
+	if hard < soft {
+		hard = soft
+	}
+
+	return soft, hard
+}
+
+// topTrackCacheKey is used to build the cache key of a top track, whose names and lyrics translation depend on the language.
+// It returns the cache key.
+func topTrackCacheKey(key, lang string) string {
+	return fmt.Sprintf("%v:%v", key, lang)
+}
+
+// serveCachedTopTrack reads the top track cached under the given key into resp.
+// A stale top track is served as is while a background refresh fetches a fresh one, unless the cache mode forbids
+// calling the vendors.
+// It returns true if the top track was served from cache.
+func serveCachedTopTrack(reqCtx context.Context, mode components.CacheMode, key string, cache utils.Cache, resp *RegionalTopTrackResponse, fetch topTrackFetcher) bool {
+	cached := new(RegionalTopTrackResponse)
+	storedAt, ok := components.GetTimedRespFromCache(mode.Reads(), key, cache, cached)
+	if !ok {
+		return false
+	}
+
+	soft, hard := topTrackTTLs()
+	age := time.Since(storedAt)
+	if age >= hard {
+		log.Printf("cached top track of %v is expired", key)
+		return false
+	}
+
+	*resp = *cached
+	resp.Meta.Cache = &CacheStatus{Age: int(age.Seconds()), Stale: age >= soft}
+	if resp.Meta.Cache.Stale && mode != components.CacheOnlyIfCached {
+		revalidateTopTrack(reqCtx, key, fetch)
+	}
+
+	return true
+}
+
+// cacheTopTrack stores the given top track under the given key until the hard TTL, if it is fully enriched.
+func cacheTopTrack(mode components.CacheMode, key string, cache utils.Cache, resp *RegionalTopTrackResponse) {
+	_, hard := topTrackTTLs()
+	components.CacheTimedResp(mode.Writes() && resp.isEnriched(), key, cache, resp, hard)
+}
+
+// revalidateTopTrack refreshes the top track cached under the given key in the background.
+// Only one refresh per key runs at a time in the process, the callers meanwhile keep being served the stale top track.
+func revalidateTopTrack(reqCtx context.Context, key string, fetch topTrackFetcher) {
+	revalidatingMu.Lock()
+	if revalidating[key] {
+		revalidatingMu.Unlock()
+		return
+	}
+	revalidating[key] = true
+	revalidatingMu.Unlock()
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic while refreshing the cached top track of %v: %v", key, r)
+			}
+			revalidatingMu.Lock()
+			delete(revalidating, key)
+			revalidatingMu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(reqCtx), revalidateTimeout)
+		defer cancel()
+
+		resp := new(RegionalTopTrackResponse)
+		if err := fetch(ctx, resp); err != nil {
+			log.Printf("error refreshing the cached top track of %v: %v", key, err)
+			return
+		}
+
+		cache := newRevalidationCache()
+		defer func() {
+			_ = cache.Close()
+		}()
+		cacheTopTrack(components.CacheDefault, key, cache, resp)
+		log.Printf("refreshed the cached top track of %v", key)
+	}()
+}
+
+// envDurationOrDefault parses the given duration config value.
+// It returns the parsed value or the default if the value is missing or not a positive duration.
+func envDurationOrDefault(val string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(val); err == nil && d > 0 {
+		return d
+	}
+
+	return def
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"geomelody/components"
+	"geomelody/components/artist"
 	"geomelody/constants"
 	"geomelody/utils"
 
@@ -49,6 +53,17 @@ func TestRegionalTopTrackForm_Valid(t *testing.T) {
 			hasErr: true,
 			err:    "`country` not found in our database. Please check the country input param, it should follow the ISO 3166-1-Alpha-2 code format",
 		},
+		{
+			name: "should fail when cache mode is unknown",
+			vars: vars{
+				form: &RegionalTopTrackForm{
+					Country: "in",
+					Cache:   "forever",
+				},
+			},
+			hasErr: true,
+			err:    "`cache` parameter is invalid",
+		},
 		{
 			name: "should success to retrieve the ISO 3166-1 format country from the given ISO 3166-1-Alpha-2 country format",
 			vars: vars{
@@ -229,6 +244,7 @@ func TestTopTrackComponent_GetRegionalTopTrack(t *testing.T) {
 		want   string
 		hasErr bool
 		err    string
+		status int
 	}{
 		{
 			name: "should success to fetch the top track of the region",
@@ -243,7 +259,23 @@ func TestTopTrackComponent_GetRegionalTopTrack(t *testing.T) {
 					"x-mock-api": "default",
 				},
 			},
-			want: `{ "meta": { "country": "India" }, "track": { "rank": 1, "name": "Yellow", "duration": "267", "listeners": 2531979, "url": "https://www.last.fm/music/Coldplay/_/Yellow", "artists_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay", "images": [ { "#text": "https://lastfm.freetls.fastly.net/i/u/34s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "small" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/64s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "medium" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/174s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "large" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "extralarge" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "mega" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "" } ], "summary": "Coldplay is a British alternative rock and britpop band formed in London in 1997. They consist of vocalist and pianist Chris Martin, guitarist Jonny Buckland, bassist Guy Berryman, drummer Will Champion and creative director Phil Harvey. They met at University College London and began playing music together from 1996 to 1998, initially calling themselves Starfish. Coldplay's music incorporates elements of soft rock, pop rock, piano rock, and post-britpop. \u003ca href=\"https://www.last.fm/music/Coldplay\"\u003eRead more on Last.fm\u003c/a\u003e", "Stats": { "listeners": 7296979, "play_count": 554561574 } }, "lyrics": "" }, "TrackSuggestion": [ { "name": "The Scientist", "match": 1, "duration": 309, "playCount": 20959436, "url": "https://www.last.fm/music/Coldplay/_/The+Scientist", "artist_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay" } }, { "name": "Sparks", "match": 0.962653, "duration": 269, "playCount": 16814271, "url": "https://www.last.fm/music/Coldplay/_/Sparks", "artist_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay" } }, { "name": "Somewhere Only We Know", "match": 0.567258, "duration": 234, "playCount": 15265085, "url": "https://www.last.fm/music/Keane/_/Somewhere+Only+We+Know", "artist_info": { "name": "Keane", "url": "https://www.last.fm/music/Keane" } }, { "name": "Chasing Cars", "match": 0.396943, "duration": 0, "playCount": 15387887, "url": "https://www.last.fm/music/Snow+Patrol/_/Chasing+Cars", "artist_info": { "name": "Snow Patrol", "url": "https://www.last.fm/music/Snow+Patrol" } }, { "name": "Iris", "match": 0.394981, "duration": 289, "playCount": 10365653, "url": "https://www.last.fm/music/Goo+Goo+Dolls/_/Iris", "artist_info": { "name": "Goo Goo Dolls", "url": "https://www.last.fm/music/Goo+Goo+Dolls" } } ] }`,
+			want: `{ "meta": { "chart": "geo", "country": "India" }, "track": { "rank": 1, "name": "Yellow", "duration": "267", "listeners": 2531979, "url": "https://www.last.fm/music/Coldplay/_/Yellow", "artists_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay", "images": [ { "#text": "https://lastfm.freetls.fastly.net/i/u/34s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "small" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/64s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "medium" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/174s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "large" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "extralarge" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "mega" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "" } ], "summary": "Coldplay is a British alternative rock and britpop band formed in London in 1997. They consist of vocalist and pianist Chris Martin, guitarist Jonny Buckland, bassist Guy Berryman, drummer Will Champion and creative director Phil Harvey. They met at University College London and began playing music together from 1996 to 1998, initially calling themselves Starfish. Coldplay's music incorporates elements of soft rock, pop rock, piano rock, and post-britpop. \u003ca href=\"https://www.last.fm/music/Coldplay\"\u003eRead more on Last.fm\u003c/a\u003e", "Stats": { "listeners": 7296979, "play_count": 554561574 } }, "lyrics": "" }, "TrackSuggestion": [ { "name": "The Scientist", "match": 1, "duration": 309, "playCount": 20959436, "url": "https://www.last.fm/music/Coldplay/_/The+Scientist", "artist_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay" } }, { "name": "Sparks", "match": 0.962653, "duration": 269, "playCount": 16814271, "url": "https://www.last.fm/music/Coldplay/_/Sparks", "artist_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay" } }, { "name": "Somewhere Only We Know", "match": 0.567258, "duration": 234, "playCount": 15265085, "url": "https://www.last.fm/music/Keane/_/Somewhere+Only+We+Know", "artist_info": { "name": "Keane", "url": "https://www.last.fm/music/Keane" } }, { "name": "Chasing Cars", "match": 0.396943, "duration": 0, "playCount": 15387887, "url": "https://www.last.fm/music/Snow+Patrol/_/Chasing+Cars", "artist_info": { "name": "Snow Patrol", "url": "https://www.last.fm/music/Snow+Patrol" } }, { "name": "Iris", "match": 0.394981, "duration": 289, "playCount": 10365653, "url": "https://www.last.fm/music/Goo+Goo+Dolls/_/Iris", "artist_info": { "name": "Goo Goo Dolls", "url": "https://www.last.fm/music/Goo+Goo+Dolls" } } ], "enrichment": { "artist_info": { "status": 200 }, "lyrics": { "status": 200 }, "suggestions": { "status": 200 } } }`,
+		},
+		{
+			name: "should success to fetch the top track of the region without the failed lyrics",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopTrackForm{
+					Country: "in",
+				},
+				headers: map[string]string{
+					"x-mock-api":            "default",
+					"x-mock-api-gettrackid": "quota_exceeded",
+				},
+			},
+			want: `{ "meta": { "chart": "geo", "country": "India" }, "track": { "rank": 1, "name": "Yellow", "duration": "267", "listeners": 2531979, "url": "https://www.last.fm/music/Coldplay/_/Yellow", "artists_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay", "images": [ { "#text": "https://lastfm.freetls.fastly.net/i/u/34s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "small" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/64s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "medium" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/174s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "large" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "extralarge" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "mega" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "" } ], "summary": "Coldplay is a British alternative rock and britpop band formed in London in 1997. They consist of vocalist and pianist Chris Martin, guitarist Jonny Buckland, bassist Guy Berryman, drummer Will Champion and creative director Phil Harvey. They met at University College London and began playing music together from 1996 to 1998, initially calling themselves Starfish. Coldplay's music incorporates elements of soft rock, pop rock, piano rock, and post-britpop. \u003ca href=\"https://www.last.fm/music/Coldplay\"\u003eRead more on Last.fm\u003c/a\u003e", "Stats": { "listeners": 7296979, "play_count": 554561574 } }, "lyrics": "" }, "TrackSuggestion": [ { "name": "The Scientist", "match": 1, "duration": 309, "playCount": 20959436, "url": "https://www.last.fm/music/Coldplay/_/The+Scientist", "artist_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay" } }, { "name": "Sparks", "match": 0.962653, "duration": 269, "playCount": 16814271, "url": "https://www.last.fm/music/Coldplay/_/Sparks", "artist_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay" } }, { "name": "Somewhere Only We Know", "match": 0.567258, "duration": 234, "playCount": 15265085, "url": "https://www.last.fm/music/Keane/_/Somewhere+Only+We+Know", "artist_info": { "name": "Keane", "url": "https://www.last.fm/music/Keane" } }, { "name": "Chasing Cars", "match": 0.396943, "duration": 0, "playCount": 15387887, "url": "https://www.last.fm/music/Snow+Patrol/_/Chasing+Cars", "artist_info": { "name": "Snow Patrol", "url": "https://www.last.fm/music/Snow+Patrol" } }, { "name": "Iris", "match": 0.394981, "duration": 289, "playCount": 10365653, "url": "https://www.last.fm/music/Goo+Goo+Dolls/_/Iris", "artist_info": { "name": "Goo Goo Dolls", "url": "https://www.last.fm/music/Goo+Goo+Dolls" } } ], "enrichment": { "artist_info": { "status": 200 }, "lyrics": { "status": 502, "error": "GetTrackID vendor API error, quota exceeded (code 402): Payment Required" }, "suggestions": { "status": 200 } } }`,
 		},
 		{
 			name: "should fail to fetch the top track of the region",
@@ -261,6 +293,40 @@ func TestTopTrackComponent_GetRegionalTopTrack(t *testing.T) {
 			hasErr: true,
 			err:    "error",
 		},
+		{
+			name: "should fail when the top track payload does not match the schema",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopTrackForm{
+					Country: "in",
+				},
+				headers: map[string]string{
+					"x-mock-api": "invalid_schema",
+				},
+			},
+			hasErr: true,
+			err:    "error while decoding GetTopTrackByCountry vendor API data",
+		},
+		{
+			name: "should fail with 502 when the top track payload misses a required field",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &RegionalTopTrackForm{
+					Country: "in",
+				},
+				headers: map[string]string{
+					"x-mock-api":                      "default",
+					"x-mock-api-gettoptrackbycountry": "missing_field",
+				},
+			},
+			hasErr: true,
+			err:    "error while decoding GetTopTrackByCountry vendor API data: track 0 is missing the required fields: listeners",
+			status: http.StatusBadGateway,
+		},
 	}
 
 	for _, tCase := range testCases {
@@ -277,6 +343,86 @@ func TestTopTrackComponent_GetRegionalTopTrack(t *testing.T) {
 			// Run test
 			got, err := ttc.GetRegionalTopTrack(form)
 
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+				if tCase.status != 0 {
+					assert.Equalf(t, tCase.status, ttc.GetComponentAppError().Status, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				tempWant := new(RegionalTopTrackResponse)
+				_ = json.Unmarshal([]byte(tCase.want), tempWant)
+				assert.Equal(t, tempWant, got, "case: %v", tCase)
+			}
+		})
+	}
+
+}
+
+func TestTopTrackComponent_GetGlobalTopTrack(t *testing.T) {
+	type vars struct {
+		component components.BaseComponent
+
+		form *GlobalTopTrackForm
+
+		headers map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   string
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to fetch the worldwide top track",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &GlobalTopTrackForm{},
+				headers: map[string]string{
+					"x-mock-api": "default",
+				},
+			},
+			want: `{ "meta": { "chart": "global" }, "track": { "rank": 1, "name": "Yellow", "duration": "267", "listeners": 2531979, "url": "https://www.last.fm/music/Coldplay/_/Yellow", "artists_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay", "images": [ { "#text": "https://lastfm.freetls.fastly.net/i/u/34s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "small" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/64s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "medium" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/174s/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "large" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "extralarge" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "mega" }, { "#text": "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png", "size": "" } ], "summary": "Coldplay is a British alternative rock and britpop band formed in London in 1997. They consist of vocalist and pianist Chris Martin, guitarist Jonny Buckland, bassist Guy Berryman, drummer Will Champion and creative director Phil Harvey. They met at University College London and began playing music together from 1996 to 1998, initially calling themselves Starfish. Coldplay's music incorporates elements of soft rock, pop rock, piano rock, and post-britpop. \u003ca href=\"https://www.last.fm/music/Coldplay\"\u003eRead more on Last.fm\u003c/a\u003e", "Stats": { "listeners": 7296979, "play_count": 554561574 } }, "lyrics": "" }, "TrackSuggestion": [ { "name": "The Scientist", "match": 1, "duration": 309, "playCount": 20959436, "url": "https://www.last.fm/music/Coldplay/_/The+Scientist", "artist_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay" } }, { "name": "Sparks", "match": 0.962653, "duration": 269, "playCount": 16814271, "url": "https://www.last.fm/music/Coldplay/_/Sparks", "artist_info": { "name": "Coldplay", "url": "https://www.last.fm/music/Coldplay" } }, { "name": "Somewhere Only We Know", "match": 0.567258, "duration": 234, "playCount": 15265085, "url": "https://www.last.fm/music/Keane/_/Somewhere+Only+We+Know", "artist_info": { "name": "Keane", "url": "https://www.last.fm/music/Keane" } }, { "name": "Chasing Cars", "match": 0.396943, "duration": 0, "playCount": 15387887, "url": "https://www.last.fm/music/Snow+Patrol/_/Chasing+Cars", "artist_info": { "name": "Snow Patrol", "url": "https://www.last.fm/music/Snow+Patrol" } }, { "name": "Iris", "match": 0.394981, "duration": 289, "playCount": 10365653, "url": "https://www.last.fm/music/Goo+Goo+Dolls/_/Iris", "artist_info": { "name": "Goo Goo Dolls", "url": "https://www.last.fm/music/Goo+Goo+Dolls" } } ], "enrichment": { "artist_info": { "status": 200 }, "lyrics": { "status": 200 }, "suggestions": { "status": 200 } } }`,
+		},
+		{
+			name: "should fail to fetch the worldwide top track",
+			vars: vars{
+				component: components.BaseComponent{
+					ReqCtx: context.Background(),
+				},
+				form: &GlobalTopTrackForm{},
+				headers: map[string]string{
+					"x-mock-api": "error_response",
+				},
+			},
+			hasErr: true,
+			err:    "error",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			form := tCase.vars.form
+			ttc := &TopTrackComponent{
+				BaseComponent: tCase.vars.component,
+			}
+			ctx := ttc.ReqCtx
+			ctx = context.WithValue(ctx, "x-mock-headers", tCase.vars.headers)
+			ttc.ReqCtx = ctx
+
+			// Run test
+			got, err := ttc.GetGlobalTopTrack(form)
+
 			// Assert
 			if tCase.hasErr {
 				if assert.Errorf(t, err, "case: %v", tCase) {
@@ -290,5 +436,503 @@ func TestTopTrackComponent_GetRegionalTopTrack(t *testing.T) {
 			}
 		})
 	}
+}
+
+func TestTopTrackComponent_GetGlobalTopTrackCacheModes(t *testing.T) {
+	type vars struct {
+		form *GlobalTopTrackForm
+
+		cached    string
+		providers *Providers
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want       string
+		wantCached string
+		hasErr     bool
+		err        error
+		status     int
+	}{
+		{
+			name: "should serve the cached response in default mode",
+			vars: vars{
+				form:      &GlobalTopTrackForm{Cache: components.CacheDefault},
+				cached:    "Cached",
+				providers: fakeProviders(fakeProvider{}),
+			},
+			want:       "Cached",
+			wantCached: "Cached",
+		},
+		{
+			name: "should fetch and store the response on a miss in default mode",
+			vars: vars{
+				form:      &GlobalTopTrackForm{UseCache: true},
+				providers: fakeProviders(fakeProvider{}),
+			},
+			want:       "Kesariya",
+			wantCached: "Kesariya",
+		},
+		{
+			name: "should neither read nor store the response in bypass mode",
+			vars: vars{
+				form:      &GlobalTopTrackForm{Cache: components.CacheBypass, UseCache: true},
+				cached:    "Cached",
+				providers: fakeProviders(fakeProvider{}),
+			},
+			want:       "Kesariya",
+			wantCached: "Cached",
+		},
+		{
+			name: "should skip the cached response but overwrite it in refresh mode",
+			vars: vars{
+				form:      &GlobalTopTrackForm{Cache: components.CacheRefresh},
+				cached:    "Cached",
+				providers: fakeProviders(fakeProvider{}),
+			},
+			want:       "Kesariya",
+			wantCached: "Kesariya",
+		},
+		{
+			name: "should serve the cached response in only-if-cached mode",
+			vars: vars{
+				form:      &GlobalTopTrackForm{Cache: components.CacheOnlyIfCached},
+				cached:    "Cached",
+				providers: fakeProviders(fakeProvider{chartErr: errors.New("vendor called")}),
+			},
+			want:       "Cached",
+			wantCached: "Cached",
+		},
+		{
+			name: "should fail with 504 without calling the vendors on a miss in only-if-cached mode",
+			vars: vars{
+				form:      &GlobalTopTrackForm{Cache: components.CacheOnlyIfCached},
+				providers: fakeProviders(fakeProvider{chartErr: errors.New("vendor called")}),
+			},
+			hasErr: true,
+			err:    components.ErrNotCached,
+			status: http.StatusGatewayTimeout,
+		},
+		{
+			name: "should fail when the cache mode is unknown",
+			vars: vars{
+				form:      &GlobalTopTrackForm{Cache: "sometimes"},
+				providers: fakeProviders(fakeProvider{}),
+			},
+			hasErr: true,
+			err:    errors.New("`cache` parameter is invalid, it should be one of default, bypass, refresh or only-if-cached"),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			cache := utils.NewLRUCache(10)
+			if tCase.vars.cached != "" {
+				cached := new(RegionalTopTrackResponse)
+				cached.Track.Name = tCase.vars.cached
+				components.CacheTimedResp(true, topTrackCacheKey(globalChart, ""), cache, cached, time.Hour)
+			}
+			ttc := &TopTrackComponent{
+				BaseComponent: components.BaseComponent{
+					ReqCtx: context.Background(),
+					Cache:  cache,
+				},
+				Providers: tCase.vars.providers,
+			}
+
+			// Run test
+			got, err := ttc.GetGlobalTopTrack(tCase.vars.form)
+
+			// Assert
+			if tCase.hasErr {
+				assert.Equalf(t, tCase.err, err, "case: %v", tCase)
+				assert.Equalf(t, tCase.status, ttc.GetComponentAppError().Status, "case: %v", tCase)
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Equalf(t, tCase.want, got.Track.Name, "case: %v", tCase)
+				cached := new(RegionalTopTrackResponse)
+				if _, ok := components.GetTimedRespFromCache(true, topTrackCacheKey(globalChart, ""), cache, cached); assert.Truef(t, ok, "case: %v", tCase) {
+					assert.Equalf(t, tCase.wantCached, cached.Track.Name, "case: %v", tCase)
+				}
+			}
+		})
+	}
+}
+
+func TestTopTrackComponent_GetGlobalTopTrackStaleWhileRevalidate(t *testing.T) {
+	type vars struct {
+		mode    components.CacheMode
+		softTTL string
+		hardTTL string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want        string
+		wantStatus  *CacheStatus
+		wantCached  string
+		revalidated bool
+	}{
+		{
+			name: "should serve the fresh cached response without refreshing it",
+			vars: vars{
+				mode: components.CacheDefault,
+			},
+			want:       "Cached",
+			wantStatus: &CacheStatus{Age: 0, Stale: false},
+			wantCached: "Cached",
+		},
+		{
+			name: "should serve the stale cached response and refresh it in the background",
+			vars: vars{
+				mode:    components.CacheDefault,
+				softTTL: "1ns",
+				hardTTL: "1h",
+			},
+			want:        "Cached",
+			wantStatus:  &CacheStatus{Age: 0, Stale: true},
+			wantCached:  "Kesariya",
+			revalidated: true,
+		},
+		{
+			name: "should serve the stale cached response without refreshing it in only-if-cached mode",
+			vars: vars{
+				mode:    components.CacheOnlyIfCached,
+				softTTL: "1ns",
+				hardTTL: "1h",
+			},
+			want:       "Cached",
+			wantStatus: &CacheStatus{Age: 0, Stale: true},
+			wantCached: "Cached",
+		},
+		{
+			name: "should fetch the response once the cached one is past the hard TTL",
+			vars: vars{
+				mode:    components.CacheDefault,
+				softTTL: "1ns",
+				hardTTL: "1ns",
+			},
+			want: "Kesariya",
+		},
+	}
+
+	defer func(newCache func() utils.Cache) {
+		newRevalidationCache = newCache
+		constants.TOP_TRACK_CACHE_SOFT_TTL = ""
+		constants.TOP_TRACK_CACHE_HARD_TTL = ""
+	}(newRevalidationCache)
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			constants.TOP_TRACK_CACHE_SOFT_TTL = tCase.vars.softTTL
+			constants.TOP_TRACK_CACHE_HARD_TTL = tCase.vars.hardTTL
+			cache := utils.NewLRUCache(10)
+			newRevalidationCache = func() utils.Cache {
+				return cache
+			}
+			cached := new(RegionalTopTrackResponse)
+			cached.Track.Name = "Cached"
+			components.CacheTimedResp(true, topTrackCacheKey(globalChart, ""), cache, cached, time.Hour)
+			ttc := &TopTrackComponent{
+				BaseComponent: components.BaseComponent{
+					ReqCtx: context.Background(),
+					Cache:  cache,
+				},
+				Providers: fakeProviders(fakeProvider{}),
+			}
+
+			// Run test
+			got, err := ttc.GetGlobalTopTrack(&GlobalTopTrackForm{Cache: tCase.vars.mode})
+
+			// Assert
+			if assert.NoErrorf(t, err, "case: %v", tCase) {
+				assert.Equalf(t, tCase.want, got.Track.Name, "case: %v", tCase)
+				assert.Equalf(t, tCase.wantStatus, got.Meta.Cache, "case: %v", tCase)
+			}
+			if tCase.revalidated {
+				assert.Eventuallyf(t, func() bool {
+					refreshed := new(RegionalTopTrackResponse)
+					_, ok := components.GetTimedRespFromCache(true, topTrackCacheKey(globalChart, ""), cache, refreshed)
+					return ok && refreshed.Track.Name == tCase.wantCached
+				}, time.Second, 10*time.Millisecond, "case: %v", tCase)
+				assert.Eventuallyf(t, func() bool {
+					revalidatingMu.Lock()
+					defer revalidatingMu.Unlock()
+					return len(revalidating) == 0
+				}, time.Second, 10*time.Millisecond, "case: %v", tCase)
+			} else if tCase.wantCached != "" {
+				refreshed := new(RegionalTopTrackResponse)
+				_, ok := components.GetTimedRespFromCache(true, topTrackCacheKey(globalChart, ""), cache, refreshed)
+				assert.Truef(t, ok, "case: %v", tCase)
+				assert.Equalf(t, tCase.wantCached, refreshed.Track.Name, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestTopTrackComponent_GetGlobalTopTrackLang(t *testing.T) {
+	type vars struct {
+		form *GlobalTopTrackForm
+
+		headers map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want       string
+		wantArtist string
+		translated bool
+		hasErr     bool
+		err        string
+	}{
+		{
+			name: "should use the English names of the track by default",
+			vars: vars{
+				form:    &GlobalTopTrackForm{Cache: components.CacheDefault},
+				headers: map[string]string{"x-mock-api": "default", "x-mock-api-gettrackid": "translated"},
+			},
+			want:       "Yellow",
+			wantArtist: "Coldplay",
+		},
+		{
+			name: "should use the names of the track translated in the given language",
+			vars: vars{
+				form:    &GlobalTopTrackForm{Cache: components.CacheDefault, Lang: " JA "},
+				headers: map[string]string{"x-mock-api": "default", "x-mock-api-gettrackid": "translated"},
+			},
+			want:       "イエロー",
+			wantArtist: "コールドプレイ",
+			translated: true,
+		},
+		{
+			name: "should fail when lang is invalid",
+			vars: vars{
+				form: &GlobalTopTrackForm{Lang: "japanese"},
+			},
+			hasErr: true,
+			err:    "`lang` parameter is invalid",
+		},
+	}
+
+	// The cache is shared by the cases, so that a cached top track in one language is never served in another one.
+	cache := utils.NewLRUCache(10)
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			ttc := &TopTrackComponent{
+				BaseComponent: components.BaseComponent{
+					ReqCtx: context.WithValue(context.Background(), "x-mock-headers", tCase.vars.headers),
+					Cache:  cache,
+				},
+			}
+
+			// Run test
+			got, err := ttc.GetGlobalTopTrack(tCase.vars.form)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.Equalf(t, tCase.want, got.Track.Name, "case: %v", tCase)
+				assert.Equalf(t, tCase.wantArtist, got.Track.ArtistsInfo.Name, "case: %v", tCase)
+				if tCase.translated {
+					if assert.NotNilf(t, got.Track.Translation, "case: %v", tCase) {
+						assert.Truef(t, got.Track.Translation.LyricsTranslated, "case: %v", tCase)
+						assert.Equalf(t, [][]string{{"星を見て", "あなたのためにどんなに輝いているか"}}, got.Track.Translation.Stanzas, "case: %v", tCase)
+					}
+				} else {
+					assert.Nilf(t, got.Track.Translation, "case: %v", tCase)
+				}
+				assert.Nilf(t, got.Meta.Cache, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+// blockingProvider is an artist, lyrics and similarity provider whose steps only succeed when all of them are in
+// flight at once, so that running the enrichment steps one after the other fails.
+type blockingProvider struct {
+	fakeProvider
 
+	inFlight sync.WaitGroup
+	timeout  time.Duration
+
+	// lyricsDone is closed once the lyrics step returned its translated names.
+	lyricsDone chan struct{}
+	// artistNameSeen is the artist name of the response the artist step saw after the lyrics step was done.
+	artistNameSeen string
+	// panicStep is the name of the step which panics.
+	panicStep string
+}
+
+func newBlockingProvider(f fakeProvider, panicStep string) *blockingProvider {
+	bp := &blockingProvider{
+		fakeProvider: f,
+		timeout:      2 * time.Second,
+		lyricsDone:   make(chan struct{}),
+		panicStep:    panicStep,
+	}
+	bp.inFlight.Add(3)
+
+	return bp
+}
+
+// waitAll is used to wait for the other steps to be in flight.
+// It returns error if they are not in flight before the timeout.
+func (bp *blockingProvider) waitAll(step string) error {
+	bp.inFlight.Done()
+	all := make(chan struct{})
+	go func() {
+		bp.inFlight.Wait()
+		close(all)
+	}()
+
+	select {
+	case <-all:
+	case <-time.After(bp.timeout):
+		return fmt.Errorf("%v step ran while the other steps were not in flight", step)
+	}
+	if bp.panicStep == step {
+		panic(step + " step failed")
+	}
+
+	return nil
+}
+
+func (bp *blockingProvider) ArtistInfo(ctx context.Context, artistName string, ai *artist.ArtistInfo) error {
+	if err := bp.waitAll("artist"); err != nil {
+		return err
+	}
+	<-bp.lyricsDone
+	bp.artistNameSeen = ai.Name
+
+	return bp.fakeProvider.ArtistInfo(ctx, artistName, ai)
+}
+
+func (bp *blockingProvider) TrackLyrics(ctx context.Context, trackName, artistName, lang string, lt *LyricsText) (*LyricsTranslation, error) {
+	defer close(bp.lyricsDone)
+	if err := bp.waitAll("lyrics"); err != nil {
+		return nil, err
+	}
+
+	return bp.fakeProvider.TrackLyrics(ctx, trackName, artistName, lang, lt)
+}
+
+func (bp *blockingProvider) SimilarTracks(ctx context.Context, trackName, artistName string) ([]TrackSuggestion, error) {
+	if err := bp.waitAll("suggestions"); err != nil {
+		return nil, err
+	}
+
+	return bp.fakeProvider.SimilarTracks(ctx, trackName, artistName)
+}
+
+func TestEnrichTopTrack(t *testing.T) {
+	type vars struct {
+		provider fakeProvider
+
+		panicStep string
+	}
+
+	type want struct {
+		track      string
+		artist     string
+		suggestion string
+
+		artistInfo  EnrichmentStatus
+		lyrics      EnrichmentStatus
+		suggestions EnrichmentStatus
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want want
+	}{
+		{
+			name: "should success to run the enrichment steps concurrently",
+			vars: vars{
+				provider: fakeProvider{},
+			},
+			want: want{
+				track:       "Kesariya",
+				artist:      "Arijit Singh",
+				suggestion:  "similar to Kesariya",
+				artistInfo:  EnrichmentStatus{Status: http.StatusOK},
+				lyrics:      EnrichmentStatus{Status: http.StatusOK},
+				suggestions: EnrichmentStatus{Status: http.StatusOK},
+			},
+		},
+		{
+			name: "should apply the translated names only once all the steps are done",
+			vars: vars{
+				provider: fakeProvider{
+					translated: &LyricsTranslation{TrackName: "Saffron", ArtistName: "Arijit Singh (EN)"},
+				},
+			},
+			want: want{
+				track:       "Saffron",
+				artist:      "Arijit Singh (EN)",
+				suggestion:  "similar to Kesariya",
+				artistInfo:  EnrichmentStatus{Status: http.StatusOK},
+				lyrics:      EnrichmentStatus{Status: http.StatusOK},
+				suggestions: EnrichmentStatus{Status: http.StatusOK},
+			},
+		},
+		{
+			name: "should report the panicking step in its enrichment status",
+			vars: vars{
+				provider:  fakeProvider{},
+				panicStep: "suggestions",
+			},
+			want: want{
+				track:       "Kesariya",
+				artist:      "Arijit Singh",
+				artistInfo:  EnrichmentStatus{Status: http.StatusOK},
+				lyrics:      EnrichmentStatus{Status: http.StatusOK},
+				suggestions: EnrichmentStatus{Status: http.StatusInternalServerError, Error: "enrichment step panicked: suggestions step failed"},
+			},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			bp := newBlockingProvider(tCase.vars.provider, tCase.vars.panicStep)
+			providers := Providers{Artist: bp, Lyrics: bp, Similarity: bp}
+			resp := new(RegionalTopTrackResponse)
+			resp.Track.Name = "Kesariya"
+			resp.Track.ArtistsInfo.Name = "Arijit Singh"
+
+			// Run test
+			enrichTopTrack(context.Background(), providers, "", resp)
+
+			// Assert
+			assert.Equalf(t, tCase.want.artistInfo, resp.Enrichment.ArtistInfo, "case: %v", tCase)
+			assert.Equalf(t, tCase.want.lyrics, resp.Enrichment.Lyrics, "case: %v", tCase)
+			assert.Equalf(t, tCase.want.suggestions, resp.Enrichment.Suggestions, "case: %v", tCase)
+			assert.Equalf(t, tCase.want.track, resp.Track.Name, "case: %v", tCase)
+			assert.Equalf(t, tCase.want.artist, resp.Track.ArtistsInfo.Name, "case: %v", tCase)
+			assert.Equalf(t, "Arijit Singh", bp.artistNameSeen, "case: %v", tCase)
+			if tCase.want.suggestion != "" && assert.Lenf(t, resp.TrackSuggestion, 1, "case: %v", tCase) {
+				assert.Equalf(t, tCase.want.suggestion, resp.TrackSuggestion[0].Name, "case: %v", tCase)
+			}
+		})
+	}
 }
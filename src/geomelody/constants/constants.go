@@ -20,6 +20,33 @@ var (
 	REDIS_HOST           = ""
 	REDIS_PORT           = ""
 	REDIS_DEFAULT_EXPIRY = ""
+	REDIS_PASSWORD       = ""
+	REDIS_DB             = ""
+	REDIS_TLS            = ""
+
+	REDIS_MODE              = ""
+	REDIS_SENTINEL_ADDRS    = ""
+	REDIS_SENTINEL_MASTER   = ""
+	REDIS_SENTINEL_PASSWORD = ""
+	REDIS_CLUSTER_ADDRS     = ""
+
+	CACHE_BACKEND        = ""
+	CACHE_FALLBACK       = ""
+	CACHE_LRU_SIZE       = ""
+	CACHE_REDIS_COOLDOWN = ""
+
+	TOP_TRACK_CACHE_SOFT_TTL = ""
+	TOP_TRACK_CACHE_HARD_TTL = ""
+
+	BATCH_MAX_WORKERS   = ""
+	BATCH_MAX_COUNTRIES = ""
+
+	CHART_PROVIDER      = ""
+	ARTIST_PROVIDER     = ""
+	LYRICS_PROVIDER     = ""
+	SIMILARITY_PROVIDER = ""
+
+	INTERNAL_API_TOKEN = ""
 )
 
 func InitConstantsVars() {
@@ -34,4 +61,31 @@ func InitConstantsVars() {
 	REDIS_HOST = os.Getenv("REDIS_HOST")
 	REDIS_PORT = os.Getenv("REDIS_PORT")
 	REDIS_DEFAULT_EXPIRY = os.Getenv("REDIS_DEFAULT_EXPIRY")
+	REDIS_PASSWORD = os.Getenv("REDIS_PASSWORD")
+	REDIS_DB = os.Getenv("REDIS_DB")
+	REDIS_TLS = os.Getenv("REDIS_TLS")
+
+	REDIS_MODE = os.Getenv("REDIS_MODE")
+	REDIS_SENTINEL_ADDRS = os.Getenv("REDIS_SENTINEL_ADDRS")
+	REDIS_SENTINEL_MASTER = os.Getenv("REDIS_SENTINEL_MASTER")
+	REDIS_SENTINEL_PASSWORD = os.Getenv("REDIS_SENTINEL_PASSWORD")
+	REDIS_CLUSTER_ADDRS = os.Getenv("REDIS_CLUSTER_ADDRS")
+
+	CACHE_BACKEND = os.Getenv("CACHE_BACKEND")
+	CACHE_FALLBACK = os.Getenv("CACHE_FALLBACK")
+	CACHE_LRU_SIZE = os.Getenv("CACHE_LRU_SIZE")
+	CACHE_REDIS_COOLDOWN = os.Getenv("CACHE_REDIS_COOLDOWN")
+
+	TOP_TRACK_CACHE_SOFT_TTL = os.Getenv("TOP_TRACK_CACHE_SOFT_TTL")
+	TOP_TRACK_CACHE_HARD_TTL = os.Getenv("TOP_TRACK_CACHE_HARD_TTL")
+
+	BATCH_MAX_WORKERS = os.Getenv("BATCH_MAX_WORKERS")
+	BATCH_MAX_COUNTRIES = os.Getenv("BATCH_MAX_COUNTRIES")
+
+	CHART_PROVIDER = os.Getenv("CHART_PROVIDER")
+	ARTIST_PROVIDER = os.Getenv("ARTIST_PROVIDER")
+	LYRICS_PROVIDER = os.Getenv("LYRICS_PROVIDER")
+	SIMILARITY_PROVIDER = os.Getenv("SIMILARITY_PROVIDER")
+
+	INTERNAL_API_TOKEN = os.Getenv("INTERNAL_API_TOKEN")
 }
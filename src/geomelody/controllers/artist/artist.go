@@ -0,0 +1,58 @@
+package artist
+
+import (
+	"log"
+	"net/http"
+
+	"geomelody/components/artist"
+	"geomelody/controllers"
+	"geomelody/utils"
+)
+
+type ArtistController struct {
+	controllers.BaseController
+	Component artist.Artist
+}
+
+// UpdateComponent is used to update the component object.
+func (c *ArtistController) UpdateComponent(component interface{}) {
+	c.Component, _ = component.(artist.Artist)
+}
+
+// GetArtistByName is used to retrieve the full details of the artist with the given name, including similar artists, tags, and biography.
+// @router	/:name [get]
+func (c *ArtistController) GetArtistByName() {
+	form := c.Component.GetArtistForm()
+	form.Name = c.Ctx.Input.Param(":name")
+
+	c.serveArtist(form)
+}
+
+// GetArtistByMBID is used to retrieve the full details of the artist with the given MusicBrainz ID, including similar artists, tags, and biography.
+// @router	/mbid/:mbid [get]
+func (c *ArtistController) GetArtistByMBID() {
+	form := c.Component.GetArtistForm()
+	form.MBID = c.Ctx.Input.Param(":mbid")
+
+	c.serveArtist(form)
+}
+
+// serveArtist is used to fetch the artist details of the given form and write the response.
+func (c *ArtistController) serveArtist(form *artist.ArtistForm) {
+	var d *artist.ArtistResponse
+	var err error
+	var status int
+
+	form.UseCache, _ = c.GetBool("use_cache")
+
+	if d, err = c.Component.GetArtist(form); err != nil {
+		status = c.Component.GetComponentAppError().Status
+		log.Printf("Some error occurred: %v", err)
+	} else {
+		status = http.StatusOK
+	}
+
+	c.Data["json"] = utils.PrepareResponse(d, err, status)
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.ServeJSON()
+}
@@ -0,0 +1,47 @@
+package artist
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"geomelody/components/artist"
+	"geomelody/controllers"
+	"geomelody/utils"
+)
+
+type TopArtistController struct {
+	controllers.BaseController
+	Component artist.TopArtist
+}
+
+// UpdateComponent is used to update the component object.
+func (c *TopArtistController) UpdateComponent(component interface{}) {
+	c.Component, _ = component.(artist.TopArtist)
+}
+
+// GetRegionalTopArtists is used to retrieve the ranked top artists of the given country along with the details of each artist.
+// @router	/ [post]
+func (c *TopArtistController) GetRegionalTopArtists() {
+	var d *artist.RegionalTopArtistsResponse
+	var err error
+	var status int
+
+	form := c.Component.GetRegionalTopArtistsForm()
+
+	if err = json.Unmarshal(c.GetRequestBody(), form); err != nil {
+		status = http.StatusInternalServerError
+	} else if d, err = c.Component.GetRegionalTopArtists(form); err != nil {
+		status = c.Component.GetComponentAppError().Status
+	}
+
+	if err != nil {
+		log.Printf("Some error occurred: %v", err)
+	} else {
+		status = http.StatusOK
+	}
+
+	c.Data["json"] = utils.PrepareResponse(d, err, status)
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.ServeJSON()
+}
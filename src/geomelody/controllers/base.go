@@ -12,7 +12,6 @@ import (
 	"geomelody/utils"
 
 	"github.com/beego/beego/v2/server/web"
-	"github.com/gomodule/redigo/redis"
 )
 
 type Preparer interface {
@@ -21,20 +20,16 @@ type Preparer interface {
 
 type BaseController struct {
 	web.Controller
-	ReqCtx    context.Context
-	RedisConn redis.Conn
+	ReqCtx context.Context
+	Cache  utils.Cache
 }
 
 // Prepare is called before the http action is processes, to initialize.
+// The cache falls back to the configured fallback when Redis is unreachable, so it never fails the request.
 func (c *BaseController) Prepare() {
 	requestCtx := c.Ctx.Request.Context()
 	c.ReqCtx = requestCtx
-	conn, err := utils.Conn()
-	if err != nil {
-		c.Error(err)
-	} else {
-		c.RedisConn = conn
-	}
+	c.Cache = utils.NewCache()
 
 	if app, ok := c.AppController.(Preparer); !ok {
 		// do nothing
@@ -47,12 +42,15 @@ func (c *BaseController) Prepare() {
 
 // Finish is called after the http action is processed, to clean-up
 func (c *BaseController) Finish() {
-	defer func(RedisConn redis.Conn) {
-		err := RedisConn.Close()
+	defer func(cache utils.Cache) {
+		if cache == nil {
+			return
+		}
+		err := cache.Close()
 		if err != nil {
-			log.Printf("error closing redis connection")
+			log.Printf("error closing cache")
 		}
-	}(c.RedisConn)
+	}(c.Cache)
 }
 
 // InitComponent initializes the component whose methods needs to be called.
@@ -68,9 +66,9 @@ func (c *BaseController) InitComponent() (interface{}, error) {
 	}
 
 	base := &components.BaseComponent{
-		ReqCtx:    c.ReqCtx,
-		AppError:  new(utils.AppError),
-		RedisConn: c.RedisConn,
+		ReqCtx:   c.ReqCtx,
+		AppError: new(utils.AppError),
+		Cache:    c.Cache,
 	}
 
 	return componentFn(base), nil
@@ -91,6 +89,12 @@ func (c *BaseController) GetRequestBody() []byte {
 	return body
 }
 
+// RequestCacheMode maps the Cache-Control header of the incoming request onto a cache mode.
+// It returns the cache mode, empty if the header asks for none.
+func (c *BaseController) RequestCacheMode() components.CacheMode {
+	return components.CacheModeFromHeader(c.Ctx.Input.Header("Cache-Control"))
+}
+
 // Error is used to stop execution, if any fatal error has occurred.
 func (c *BaseController) Error(err error) {
 	log.Printf("Some error occurred: %v", err)
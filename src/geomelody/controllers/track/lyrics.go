@@ -0,0 +1,135 @@
+package track
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"geomelody/components/track"
+	"geomelody/controllers"
+	"geomelody/utils"
+)
+
+type LyricsController struct {
+	controllers.BaseController
+	Component track.Lyrics
+}
+
+// UpdateComponent is used to update the component object.
+func (c *LyricsController) UpdateComponent(component interface{}) {
+	c.Component, _ = component.(track.Lyrics)
+}
+
+// GetLyrics is used to retrieve the lyrics of the given track and artist, or of the given Musixmatch track ID, along with the translation details.
+// @router	/ [post]
+func (c *LyricsController) GetLyrics() {
+	var d *track.LyricsResponse
+	var err error
+	var status int
+
+	form := c.Component.GetLyricsForm()
+
+	if err = json.Unmarshal(c.GetRequestBody(), form); err != nil {
+		status = http.StatusInternalServerError
+	} else if d, err = c.Component.GetLyrics(form); err != nil {
+		status = c.Component.GetComponentAppError().Status
+	}
+
+	if err != nil {
+		log.Printf("Some error occurred: %v", err)
+	} else {
+		status = http.StatusOK
+	}
+
+	c.Data["json"] = utils.PrepareResponse(d, err, status)
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.ServeJSON()
+}
+
+// GetSyncedLyrics is used to retrieve the time-synced lyrics of the given track and artist, or of the given Musixmatch track ID.
+// @router	/synced [post]
+func (c *LyricsController) GetSyncedLyrics() {
+	d, status, err := c.getSyncedLyrics()
+
+	c.Data["json"] = utils.PrepareResponse(d, err, status)
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.ServeJSON()
+}
+
+// DownloadSyncedLyrics is used to download the time-synced lyrics of the given track as an LRC file.
+// @router	/synced/lrc [post]
+func (c *LyricsController) DownloadSyncedLyrics() {
+	d, status, err := c.getSyncedLyrics()
+	if err != nil {
+		c.Data["json"] = utils.PrepareResponse(d, err, status)
+		c.AddHeaders(status, map[string]bool{"no_cache": true})
+		_ = c.ServeJSON()
+
+		return
+	}
+
+	fileName := lrcFileNameReplacer.Replace(fmt.Sprintf("%v - %v.lrc", d.Track.ArtistName, d.Track.Name))
+	c.Ctx.Output.Header("Content-Type", "application/octet-stream")
+	c.Ctx.Output.Header("Content-Disposition", attachmentDisposition(fileName))
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.Ctx.Output.Body([]byte(d.LRC()))
+}
+
+var lrcFileNameReplacer = strings.NewReplacer("/", "_", "\\", "_", "\"", "'", "\n", " ", "\r", " ")
+
+// attachmentDisposition is used to build the Content-Disposition header of the given file name, as per RFC 6266.
+// The plain filename is an ASCII fallback for the old clients, the UTF-8 filename* keeps the non-ASCII names intact.
+// It returns the header value.
+func attachmentDisposition(fileName string) string {
+	var ascii, encoded strings.Builder
+	for _, r := range fileName {
+		if r < 0x20 || r > 0x7e {
+			ascii.WriteByte('_')
+		} else {
+			ascii.WriteRune(r)
+		}
+	}
+	for _, b := range []byte(fileName) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			_, _ = fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+
+	return fmt.Sprintf("attachment; filename=\"%v\"; filename*=UTF-8''%v", ascii.String(), encoded.String())
+}
+
+// isAttrChar reports whether the byte may be sent as it is in an RFC 5987 ext-value.
+func isAttrChar(b byte) bool {
+	switch {
+	case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		return true
+	}
+
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
+
+func (c *LyricsController) getSyncedLyrics() (*track.SyncedLyricsResponse, int, error) {
+	var d *track.SyncedLyricsResponse
+	var err error
+	var status int
+
+	form := c.Component.GetSyncedLyricsForm()
+
+	if err = json.Unmarshal(c.GetRequestBody(), form); err != nil {
+		status = http.StatusInternalServerError
+	} else if d, err = c.Component.GetSyncedLyrics(form); err != nil {
+		status = c.Component.GetComponentAppError().Status
+	}
+
+	if err != nil {
+		log.Printf("Some error occurred: %v", err)
+	} else {
+		status = http.StatusOK
+	}
+
+	return d, status, err
+}
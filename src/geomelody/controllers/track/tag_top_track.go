@@ -0,0 +1,80 @@
+package track
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"geomelody/components/track"
+	"geomelody/controllers"
+	"geomelody/utils"
+)
+
+type TagTopTrackController struct {
+	controllers.BaseController
+	Component track.TagTopTrack
+}
+
+// UpdateComponent is used to update the component object.
+func (c *TagTopTrackController) UpdateComponent(component interface{}) {
+	c.Component, _ = component.(track.TagTopTrack)
+}
+
+// GetTagTopTrack is used to retrieve the details, lyrics, and artists of the top track of the given tag. It also provides suggestions based on the retrieved track and artist.
+// @router	/top-track [post]
+func (c *TagTopTrackController) GetTagTopTrack() {
+	var d *track.RegionalTopTrackResponse
+	var err error
+	var status int
+
+	form := c.Component.GetTagTopTrackForm()
+	// The cache mode of the body, if any, overrides the one of the Cache-Control header
+	form.Cache = c.RequestCacheMode()
+
+	if err = json.Unmarshal(c.GetRequestBody(), form); err != nil {
+		status = http.StatusInternalServerError
+	} else if d, err = c.Component.GetTagTopTrack(form); err != nil {
+		status = c.Component.GetComponentAppError().Status
+	}
+
+	if err != nil {
+		log.Printf("Some error occurred: %v", err)
+	} else {
+		status = http.StatusOK
+	}
+
+	if d != nil && d.Meta.Cache != nil {
+		c.Ctx.Output.Header("Age", strconv.Itoa(d.Meta.Cache.Age))
+	}
+
+	c.Data["json"] = utils.PrepareResponse(d, err, status)
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.ServeJSON()
+}
+
+// GetTagInfo is used to retrieve the description of the given tag.
+// @router	/info [post]
+func (c *TagTopTrackController) GetTagInfo() {
+	var d *track.TagInfoResponse
+	var err error
+	var status int
+
+	form := c.Component.GetTagInfoForm()
+
+	if err = json.Unmarshal(c.GetRequestBody(), form); err != nil {
+		status = http.StatusInternalServerError
+	} else if d, err = c.Component.GetTagInfo(form); err != nil {
+		status = c.Component.GetComponentAppError().Status
+	}
+
+	if err != nil {
+		log.Printf("Some error occurred: %v", err)
+	} else {
+		status = http.StatusOK
+	}
+
+	c.Data["json"] = utils.PrepareResponse(d, err, status)
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.ServeJSON()
+}
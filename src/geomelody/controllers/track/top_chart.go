@@ -0,0 +1,47 @@
+package track
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"geomelody/components/track"
+	"geomelody/controllers"
+	"geomelody/utils"
+)
+
+type TopChartController struct {
+	controllers.BaseController
+	Component track.TopChart
+}
+
+// UpdateComponent is used to update the component object.
+func (c *TopChartController) UpdateComponent(component interface{}) {
+	c.Component, _ = component.(track.TopChart)
+}
+
+// GetRegionalTopChart is used to retrieve the top N tracks of the given country along with the paging details.
+// @router	/ [post]
+func (c *TopChartController) GetRegionalTopChart() {
+	var d *track.RegionalTopChartResponse
+	var err error
+	var status int
+
+	form := c.Component.GetRegionalTopChartForm()
+
+	if err = json.Unmarshal(c.GetRequestBody(), form); err != nil {
+		status = http.StatusInternalServerError
+	} else if d, err = c.Component.GetRegionalTopChart(form); err != nil {
+		status = c.Component.GetComponentAppError().Status
+	}
+
+	if err != nil {
+		log.Printf("Some error occurred: %v", err)
+	} else {
+		status = http.StatusOK
+	}
+
+	c.Data["json"] = utils.PrepareResponse(d, err, status)
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.ServeJSON()
+}
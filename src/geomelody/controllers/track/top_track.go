@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
 
 	"geomelody/components/track"
 	"geomelody/controllers"
@@ -28,6 +29,8 @@ func (c *TopTrackController) GetRegionalTopTrack() {
 	var status int
 
 	form := c.Component.GetRegionalTopTrackForm()
+	// The cache mode of the body, if any, overrides the one of the Cache-Control header
+	form.Cache = c.RequestCacheMode()
 
 	if err = json.Unmarshal(c.GetRequestBody(), form); err != nil {
 		status = http.StatusInternalServerError
@@ -41,6 +44,70 @@ func (c *TopTrackController) GetRegionalTopTrack() {
 		status = http.StatusOK
 	}
 
+	if d != nil && d.Meta.Cache != nil {
+		c.Ctx.Output.Header("Age", strconv.Itoa(d.Meta.Cache.Age))
+	}
+
+	c.Data["json"] = utils.PrepareResponse(d, err, status)
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.ServeJSON()
+}
+
+// GetRegionalTopTrackBatch is used to retrieve the top track of each of the given countries concurrently. Failures are reported per country.
+// @router	/batch [post]
+func (c *TopTrackController) GetRegionalTopTrackBatch() {
+	var d *track.RegionalTopTrackBatchResponse
+	var err error
+	var status int
+
+	form := c.Component.GetRegionalTopTrackBatchForm()
+	// The cache mode of the body, if any, overrides the one of the Cache-Control header
+	form.Cache = c.RequestCacheMode()
+
+	if err = json.Unmarshal(c.GetRequestBody(), form); err != nil {
+		status = http.StatusInternalServerError
+	} else if d, err = c.Component.GetRegionalTopTrackBatch(form); err != nil {
+		status = c.Component.GetComponentAppError().Status
+	}
+
+	if err != nil {
+		log.Printf("Some error occurred: %v", err)
+	} else {
+		status = http.StatusOK
+	}
+
+	c.Data["json"] = utils.PrepareResponse(d, err, status)
+	c.AddHeaders(status, map[string]bool{"no_cache": true})
+	_ = c.ServeJSON()
+}
+
+// GetGlobalTopTrack is used to retrieve the details, lyrics, and artists of the worldwide top track. It also provides suggestions based on the retrieved track and artist.
+// @router	/global [post]
+func (c *TopTrackController) GetGlobalTopTrack() {
+	var d *track.RegionalTopTrackResponse
+	var err error
+	var status int
+
+	form := c.Component.GetGlobalTopTrackForm()
+	// The cache mode of the body, if any, overrides the one of the Cache-Control header
+	form.Cache = c.RequestCacheMode()
+
+	if err = json.Unmarshal(c.GetRequestBody(), form); err != nil {
+		status = http.StatusInternalServerError
+	} else if d, err = c.Component.GetGlobalTopTrack(form); err != nil {
+		status = c.Component.GetComponentAppError().Status
+	}
+
+	if err != nil {
+		log.Printf("Some error occurred: %v", err)
+	} else {
+		status = http.StatusOK
+	}
+
+	if d != nil && d.Meta.Cache != nil {
+		c.Ctx.Output.Header("Age", strconv.Itoa(d.Meta.Cache.Age))
+	}
+
 	c.Data["json"] = utils.PrepareResponse(d, err, status)
 	c.AddHeaders(status, map[string]bool{"no_cache": true})
 	_ = c.ServeJSON()
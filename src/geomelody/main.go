@@ -3,8 +3,10 @@ package main
 import (
 	"log"
 
+	"geomelody/components/track"
 	"geomelody/constants"
 	"geomelody/routers"
+	"geomelody/utils"
 
 	_ "github.com/beego/beego/v2/core/config/yaml"
 	"github.com/beego/beego/v2/server/web"
@@ -40,6 +42,24 @@ func init() {
 	}
 	constants.InitConstantsVars()
 
+	// Init the cache backend and the Redis pool behind it, before the HTTP client whose vendor rate limits depend on it
+	if err := utils.InitRedisPool(); err != nil {
+		log.Fatal("Error initializing Redis pool: ", err)
+	}
+	if err := utils.InitCache(); err != nil {
+		log.Fatal("Error initializing cache: ", err)
+	}
+
+	// Init the client used to call the vendor APIs
+	if err := utils.InitHTTPClient(); err != nil {
+		log.Fatal("Error initializing HTTP client: ", err)
+	}
+
+	// Select the providers of the top track
+	if err := track.InitProviders(); err != nil {
+		log.Fatal("Error initializing track providers: ", err)
+	}
+
 	// Init routes
 	routers.InitRoutes()
 }
@@ -7,6 +7,87 @@ import (
 
 func init() {
 
+	beego.GlobalControllerRouter["geomelody/controllers/artist:ArtistController"] = append(beego.GlobalControllerRouter["geomelody/controllers/artist:ArtistController"],
+		beego.ControllerComments{
+			Method:           "GetArtistByMBID",
+			Router:           `/mbid/:mbid`,
+			AllowHTTPMethods: []string{"get"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/artist:ArtistController"] = append(beego.GlobalControllerRouter["geomelody/controllers/artist:ArtistController"],
+		beego.ControllerComments{
+			Method:           "GetArtistByName",
+			Router:           `/:name`,
+			AllowHTTPMethods: []string{"get"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/artist:TopArtistController"] = append(beego.GlobalControllerRouter["geomelody/controllers/artist:TopArtistController"],
+		beego.ControllerComments{
+			Method:           "GetRegionalTopArtists",
+			Router:           `/`,
+			AllowHTTPMethods: []string{"post"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/track:LyricsController"] = append(beego.GlobalControllerRouter["geomelody/controllers/track:LyricsController"],
+		beego.ControllerComments{
+			Method:           "GetLyrics",
+			Router:           `/`,
+			AllowHTTPMethods: []string{"post"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/track:LyricsController"] = append(beego.GlobalControllerRouter["geomelody/controllers/track:LyricsController"],
+		beego.ControllerComments{
+			Method:           "GetSyncedLyrics",
+			Router:           `/synced`,
+			AllowHTTPMethods: []string{"post"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/track:LyricsController"] = append(beego.GlobalControllerRouter["geomelody/controllers/track:LyricsController"],
+		beego.ControllerComments{
+			Method:           "DownloadSyncedLyrics",
+			Router:           `/synced/lrc`,
+			AllowHTTPMethods: []string{"post"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/track:TagTopTrackController"] = append(beego.GlobalControllerRouter["geomelody/controllers/track:TagTopTrackController"],
+		beego.ControllerComments{
+			Method:           "GetTagInfo",
+			Router:           `/info`,
+			AllowHTTPMethods: []string{"post"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/track:TagTopTrackController"] = append(beego.GlobalControllerRouter["geomelody/controllers/track:TagTopTrackController"],
+		beego.ControllerComments{
+			Method:           "GetTagTopTrack",
+			Router:           `/top-track`,
+			AllowHTTPMethods: []string{"post"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/track:TopChartController"] = append(beego.GlobalControllerRouter["geomelody/controllers/track:TopChartController"],
+		beego.ControllerComments{
+			Method:           "GetRegionalTopChart",
+			Router:           `/`,
+			AllowHTTPMethods: []string{"post"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
 	beego.GlobalControllerRouter["geomelody/controllers/track:TopTrackController"] = append(beego.GlobalControllerRouter["geomelody/controllers/track:TopTrackController"],
 		beego.ControllerComments{
 			Method:           "GetRegionalTopTrack",
@@ -15,4 +96,22 @@ func init() {
 			MethodParams:     param.Make(),
 			Filters:          nil,
 			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/track:TopTrackController"] = append(beego.GlobalControllerRouter["geomelody/controllers/track:TopTrackController"],
+		beego.ControllerComments{
+			Method:           "GetRegionalTopTrackBatch",
+			Router:           `/batch`,
+			AllowHTTPMethods: []string{"post"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
+
+	beego.GlobalControllerRouter["geomelody/controllers/track:TopTrackController"] = append(beego.GlobalControllerRouter["geomelody/controllers/track:TopTrackController"],
+		beego.ControllerComments{
+			Method:           "GetGlobalTopTrack",
+			Router:           `/global`,
+			AllowHTTPMethods: []string{"post"},
+			MethodParams:     param.Make(),
+			Filters:          nil,
+			Params:           nil})
 }
@@ -1,10 +1,15 @@
 package routers
 
 import (
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"net/http"
 
 	"geomelody/constants"
+	"geomelody/controllers/artist"
 	"geomelody/controllers/track"
+	"geomelody/utils"
 
 	"github.com/beego/beego/v2/server/web"
 	"github.com/beego/beego/v2/server/web/context"
@@ -16,6 +21,14 @@ func InitRoutes() {
 			_ = ctx.Output.Body([]byte("i am alive"))
 		}),
 
+		web.NSNamespace("/internal",
+			web.NSBefore(authorizeInternal),
+			web.NSGet("/circuit-breakers", func(ctx *context.Context) {
+				ctx.Output.Header("Cache-Control", "no-store, max-age=0")
+				_ = ctx.Output.JSON(utils.PrepareResponse(utils.CircuitBreakerStatuses(), nil, http.StatusOK), false, false)
+			}),
+		),
+
 		web.NSNamespace("/track",
 			web.NSNamespace(
 				"/top-track",
@@ -23,8 +36,54 @@ func InitRoutes() {
 					&track.TopTrackController{},
 				),
 			),
+			web.NSNamespace(
+				"/top-chart",
+				web.NSInclude(
+					&track.TopChartController{},
+				),
+			),
+			web.NSNamespace(
+				"/lyrics",
+				web.NSInclude(
+					&track.LyricsController{},
+				),
+			),
+		),
+
+		web.NSNamespace("/tag",
+			web.NSInclude(
+				&track.TagTopTrackController{},
+			),
+		),
+
+		web.NSNamespace("/artist",
+			web.NSInclude(
+				&artist.ArtistController{},
+			),
+			web.NSNamespace(
+				"/top-artists",
+				web.NSInclude(
+					&artist.TopArtistController{},
+				),
+			),
 		),
 	)
 
 	web.AddNamespace(ns)
 }
+
+// authorizeInternal is used to restrict the internal endpoints to the callers sending INTERNAL_API_TOKEN in the
+// X-Internal-Token header. The internal endpoints are not served at all when the token is unset.
+func authorizeInternal(ctx *context.Context) {
+	token := constants.INTERNAL_API_TOKEN
+	if token == "" {
+		ctx.Output.SetStatus(http.StatusNotFound)
+		_ = ctx.Output.JSON(utils.PrepareResponse(nil, errors.New("not found"), http.StatusNotFound), false, false)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(ctx.Input.Header("X-Internal-Token")), []byte(token)) != 1 {
+		ctx.Output.SetStatus(http.StatusUnauthorized)
+		_ = ctx.Output.JSON(utils.PrepareResponse(nil, errors.New("missing or invalid internal token"), http.StatusUnauthorized), false, false)
+	}
+}
@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerConfig holds when the circuit breakers open and how they probe the vendor once open.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures which opens the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting probe requests through.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of concurrent probe requests let through while half-open.
+	HalfOpenRequests int
+}
+
+// CircuitOpenError is returned when a vendor call is short-circuited by its open breaker.
+type CircuitOpenError struct {
+	Key     string
+	RetryIn time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryIn <= 0 {
+		return fmt.Sprintf("circuit breaker of %v is half-open and already probing the vendor", e.Key)
+	}
+
+	return fmt.Sprintf("circuit breaker of %v is open, retry in %v", e.Key, e.RetryIn.Round(time.Millisecond))
+}
+
+// CircuitBreakerStatus is the state of a circuit breaker as exposed by the internal status endpoint.
+type CircuitBreakerStatus struct {
+	Key                 string       `json:"key"`
+	Name                string       `json:"name"`
+	Host                string       `json:"host"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	LastError           string       `json:"last_error,omitempty"`
+}
+
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	key, name, host string
+	state           CircuitState
+	failures        int
+	openedAt        time.Time
+	probes          int
+	lastError       string
+}
+
+var (
+	breakersMu sync.Mutex
+
+	breakerConfig = CircuitBreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenRequests: 1,
+	}
+
+	// breakers holds the circuit breakers by vendor call name and host.
+	breakers = map[string]*circuitBreaker{}
+)
+
+// SetCircuitBreakerConfig sets the config of all the circuit breakers.
+func SetCircuitBreakerConfig(cfg CircuitBreakerConfig) {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	breakerConfig = cfg
+}
+
+func getCircuitBreakerConfig() CircuitBreakerConfig {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	return breakerConfig
+}
+
+// InitCircuitBreakers reads the circuit breaker config from env.
+func InitCircuitBreakers() {
+	cfg := getCircuitBreakerConfig()
+	updateIntFromEnv("CIRCUIT_BREAKER_FAILURE_THRESHOLD", &cfg.FailureThreshold)
+	updateDurationFromEnv("CIRCUIT_BREAKER_OPEN_TIMEOUT", &cfg.OpenTimeout)
+	updateIntFromEnv("CIRCUIT_BREAKER_HALF_OPEN_REQUESTS", &cfg.HalfOpenRequests)
+	SetCircuitBreakerConfig(cfg)
+}
+
+// circuitBreakerFor returns the circuit breaker of the given vendor call name and URL host, creating it if needed.
+func circuitBreakerFor(name, rawURL string) *circuitBreaker {
+	var host string
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Host
+	}
+	key := fmt.Sprintf("%v@%v", name, host)
+
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	b, ok := breakers[key]
+	if !ok {
+		b = &circuitBreaker{key: key, name: name, host: host, state: CircuitClosed}
+		breakers[key] = b
+	}
+
+	return b
+}
+
+// CircuitBreakerStatuses returns the state of all the circuit breakers, sorted by key.
+func CircuitBreakerStatuses() []CircuitBreakerStatus {
+	breakersMu.Lock()
+	list := make([]*circuitBreaker, 0, len(breakers))
+	for _, b := range breakers {
+		list = append(list, b)
+	}
+	breakersMu.Unlock()
+
+	statuses := make([]CircuitBreakerStatus, 0, len(list))
+	for _, b := range list {
+		statuses = append(statuses, b.status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Key < statuses[j].Key
+	})
+
+	return statuses
+}
+
+// allow is used to check whether a call can go through the breaker.
+// An open breaker turns half-open once OpenTimeout is over, and then lets HalfOpenRequests probe calls through.
+// It returns CircuitOpenError if the call is short-circuited.
+func (b *circuitBreaker) allow() error {
+	cfg := getCircuitBreakerConfig()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen {
+		if wait := cfg.OpenTimeout - time.Since(b.openedAt); wait > 0 {
+			return &CircuitOpenError{Key: b.key, RetryIn: wait}
+		}
+		b.state = CircuitHalfOpen
+		b.probes = 0
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.probes >= cfg.HalfOpenRequests {
+			return &CircuitOpenError{Key: b.key}
+		}
+		b.probes++
+	}
+
+	return nil
+}
+
+// record is used to report the outcome of a call let through by the breaker.
+// Only the transient failures of the vendor count, the calls cancelled by the client are ignored.
+func (b *circuitBreaker) record(err error) {
+	cfg := getCircuitBreakerConfig()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	} else if err == nil || !isRetryable(err) {
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.failures >= cfg.FailureThreshold) {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) status() CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := CircuitBreakerStatus{
+		Key:                 b.key,
+		Name:                b.name,
+		Host:                b.host,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		s.OpenedAt = &openedAt
+	}
+
+	return s
+}
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// breakerStep is a call of the breaker, either allow or record, or a wait for the open timeout to be over.
+type breakerStep struct {
+	op  string
+	err error
+
+	// wantErr is whether allow short-circuits the call.
+	wantErr bool
+	// state and failures are those of the breaker after the step.
+	state    CircuitState
+	failures int
+}
+
+func TestCircuitBreaker_allowRecord(t *testing.T) {
+	breakersMu.Lock()
+	cfg := breakerConfig
+	breakersMu.Unlock()
+	defer SetCircuitBreakerConfig(cfg)
+
+	unavailable := &VendorError{API: "TestAPI", Kind: VendorUnavailable, Code: 503}
+	notFound := &VendorError{API: "TestAPI", Kind: VendorNotFound, Code: 404}
+	canceled := &url.Error{Op: "Get", URL: "https://ws.audioscrobbler.com/2.0/", Err: context.Canceled}
+	deadline := &url.Error{Op: "Get", URL: "https://ws.audioscrobbler.com/2.0/", Err: context.DeadlineExceeded}
+
+	testCases := []struct {
+		name string
+
+		steps []breakerStep
+	}{
+		{
+			name: "should open once the failure threshold is reached",
+			steps: []breakerStep{
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 1},
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 2},
+				{op: "allow", state: CircuitClosed, failures: 2},
+				{op: "record", err: unavailable, state: CircuitOpen, failures: 3},
+				{op: "allow", wantErr: true, state: CircuitOpen, failures: 3},
+			},
+		},
+		{
+			name: "should reset the failures on success",
+			steps: []breakerStep{
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 1},
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 2},
+				{op: "record", state: CircuitClosed, failures: 0},
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 1},
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 2},
+			},
+		},
+		{
+			name: "should reset the failures on an error which is not transient",
+			steps: []breakerStep{
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 1},
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 2},
+				{op: "record", err: notFound, state: CircuitClosed, failures: 0},
+			},
+		},
+		{
+			name: "should ignore the cancelled calls",
+			steps: []breakerStep{
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 1},
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 2},
+				{op: "record", err: canceled, state: CircuitClosed, failures: 2},
+				{op: "record", err: deadline, state: CircuitClosed, failures: 2},
+				{op: "record", err: context.Canceled, state: CircuitClosed, failures: 2},
+				{op: "record", err: unavailable, state: CircuitOpen, failures: 3},
+			},
+		},
+		{
+			name: "should let a single probe through once half-open, and close on its success",
+			steps: []breakerStep{
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 1},
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 2},
+				{op: "record", err: unavailable, state: CircuitOpen, failures: 3},
+				{op: "wait", state: CircuitOpen, failures: 3},
+				{op: "allow", state: CircuitHalfOpen, failures: 3},
+				{op: "allow", wantErr: true, state: CircuitHalfOpen, failures: 3},
+				{op: "record", state: CircuitClosed, failures: 0},
+				{op: "allow", state: CircuitClosed, failures: 0},
+				{op: "allow", state: CircuitClosed, failures: 0},
+			},
+		},
+		{
+			name: "should open again when the probe fails",
+			steps: []breakerStep{
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 1},
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 2},
+				{op: "record", err: unavailable, state: CircuitOpen, failures: 3},
+				{op: "wait", state: CircuitOpen, failures: 3},
+				{op: "allow", state: CircuitHalfOpen, failures: 3},
+				{op: "record", err: unavailable, state: CircuitOpen, failures: 4},
+				{op: "allow", wantErr: true, state: CircuitOpen, failures: 4},
+			},
+		},
+		{
+			name: "should free the probe slot when the probe is cancelled",
+			steps: []breakerStep{
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 1},
+				{op: "record", err: unavailable, state: CircuitClosed, failures: 2},
+				{op: "record", err: unavailable, state: CircuitOpen, failures: 3},
+				{op: "wait", state: CircuitOpen, failures: 3},
+				{op: "allow", state: CircuitHalfOpen, failures: 3},
+				{op: "record", err: canceled, state: CircuitHalfOpen, failures: 3},
+				{op: "allow", state: CircuitHalfOpen, failures: 3},
+				{op: "allow", wantErr: true, state: CircuitHalfOpen, failures: 3},
+			},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			openTimeout := 20 * time.Millisecond
+			SetCircuitBreakerConfig(CircuitBreakerConfig{FailureThreshold: 3, OpenTimeout: openTimeout, HalfOpenRequests: 1})
+			b := &circuitBreaker{key: "TestAPI@ws.audioscrobbler.com", state: CircuitClosed}
+
+			for i, step := range tCase.steps {
+				// Run test
+				var err error
+				switch step.op {
+				case "allow":
+					err = b.allow()
+				case "record":
+					b.record(step.err)
+				case "wait":
+					time.Sleep(openTimeout)
+				}
+
+				// Assert
+				if step.wantErr {
+					var openErr *CircuitOpenError
+					assert.Truef(t, errors.As(err, &openErr), "case: %v, step: %v", tCase.name, i)
+				} else {
+					assert.NoErrorf(t, err, "case: %v, step: %v", tCase.name, i)
+				}
+				status := b.status()
+				assert.Equalf(t, step.state, status.State, "case: %v, step: %v", tCase.name, i)
+				assert.Equalf(t, step.failures, status.ConsecutiveFailures, "case: %v, step: %v", tCase.name, i)
+			}
+		})
+	}
+}
+
+func TestCircuitOpenError_Error(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		err *CircuitOpenError
+
+		want string
+	}{
+		{
+			name: "should tell when to retry while open",
+			err:  &CircuitOpenError{Key: "GetTrackLyrics@api.musixmatch.com", RetryIn: 1500 * time.Millisecond},
+			want: "circuit breaker of GetTrackLyrics@api.musixmatch.com is open, retry in 1.5s",
+		},
+		{
+			name: "should tell the vendor is being probed while half-open",
+			err:  &CircuitOpenError{Key: "GetTrackLyrics@api.musixmatch.com"},
+			want: "circuit breaker of GetTrackLyrics@api.musixmatch.com is half-open and already probing the vendor",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Run test
+			got := tCase.err.Error()
+
+			// Assert
+			assert.Equalf(t, tCase.want, got, "case: %v", tCase)
+		})
+	}
+}
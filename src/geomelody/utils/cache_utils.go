@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"geomelody/constants"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	CacheRedis = "redis"
+	CacheLRU   = "lru"
+	CacheNoop  = "noop"
+)
+
+// ErrCacheMiss is returned by Cache.Get when the key is not cached.
+var ErrCacheMiss = errors.New("data not found in cache")
+
+// Cache stores the API responses. A Cache is used by one request at a time, but the LRU cache behind it may be shared.
+type Cache interface {
+	Get(key string) (string, error)
+	Set(key, val string, ttl time.Duration) error
+	Close() error
+}
+
+var (
+	cacheBackend  = CacheRedis
+	cacheFallback = CacheLRU
+
+	// sharedLRU is the in-process cache shared by all the requests, created on first use.
+	sharedLRU     *LRUCache
+	sharedLRUOnce sync.Once
+	lruSize       = 1000
+
+	// cacheRedisCooldown is how long the fallback cache is used without dialing Redis once it failed, so that a Redis
+	// outage does not add a connect timeout to every request.
+	cacheRedisCooldown = 5 * time.Second
+
+	cacheRedisMu        sync.Mutex
+	cacheRedisDownUntil time.Time
+)
+
+// InitCache reads the cache backend and its fallback from the constants.
+// It returns error if one of them is unknown, or if the LRU size is invalid.
+func InitCache() error {
+	if constants.CACHE_BACKEND != "" {
+		cacheBackend = constants.CACHE_BACKEND
+	}
+	if constants.CACHE_FALLBACK != "" {
+		cacheFallback = constants.CACHE_FALLBACK
+	}
+	if constants.CACHE_LRU_SIZE != "" {
+		size, err := strconv.Atoi(constants.CACHE_LRU_SIZE)
+		if err != nil || size < 1 {
+			return fmt.Errorf("invalid CACHE_LRU_SIZE: %v", constants.CACHE_LRU_SIZE)
+		}
+		lruSize = size
+	}
+	if constants.CACHE_REDIS_COOLDOWN != "" {
+		cooldown, err := time.ParseDuration(constants.CACHE_REDIS_COOLDOWN)
+		if err != nil || cooldown < 0 {
+			return fmt.Errorf("invalid CACHE_REDIS_COOLDOWN: %v", constants.CACHE_REDIS_COOLDOWN)
+		}
+		cacheRedisCooldown = cooldown
+	}
+
+	switch cacheBackend {
+	case CacheRedis, CacheLRU, CacheNoop:
+	default:
+		return fmt.Errorf("unknown cache backend: %v", cacheBackend)
+	}
+	switch cacheFallback {
+	case CacheLRU, CacheNoop:
+	default:
+		return fmt.Errorf("unknown cache fallback: %v, expected %v or %v", cacheFallback, CacheLRU, CacheNoop)
+	}
+
+	log.Printf("Initialized cache, backend: %v, fallback: %v", cacheBackend, cacheFallback)
+
+	return nil
+}
+
+// NewCache opens the configured cache backend.
+// The fallback backend is used when Redis is unreachable, so that a Redis outage never fails the requests, and Redis is
+// not dialed again until cacheRedisCooldown is over.
+// It returns the cache, which must be closed once the request is done.
+func NewCache() Cache {
+	switch cacheBackend {
+	case CacheLRU:
+		return lruCache()
+	case CacheNoop:
+		return NoopCache{}
+	}
+
+	if isCacheRedisDown() {
+		return fallbackCache()
+	}
+
+	conn, err := Conn()
+	if err != nil {
+		log.Printf("error connecting to Redis, falling back to %v cache for %v: %v", cacheFallback, cacheRedisCooldown, err)
+		markCacheRedisDown()
+		return fallbackCache()
+	}
+
+	return &RedisCache{conn: conn}
+}
+
+// fallbackCache is used to open the cache used while Redis is unreachable.
+// It returns the cache.
+func fallbackCache() Cache {
+	if cacheFallback == CacheLRU {
+		return lruCache()
+	}
+
+	return NoopCache{}
+}
+
+// isCacheRedisDown reports whether Redis failed less than cacheRedisCooldown ago.
+func isCacheRedisDown() bool {
+	cacheRedisMu.Lock()
+	defer cacheRedisMu.Unlock()
+
+	return time.Now().Before(cacheRedisDownUntil)
+}
+
+// markCacheRedisDown is used to open the fallback cache without dialing Redis until cacheRedisCooldown is over.
+func markCacheRedisDown() {
+	cacheRedisMu.Lock()
+	defer cacheRedisMu.Unlock()
+
+	cacheRedisDownUntil = time.Now().Add(cacheRedisCooldown)
+}
+
+func lruCache() *LRUCache {
+	sharedLRUOnce.Do(func() {
+		sharedLRU = NewLRUCache(lruSize)
+	})
+
+	return sharedLRU
+}
+
+// RedisCache is the cache backed by a Redis connection.
+type RedisCache struct {
+	conn redis.Conn
+}
+
+func (c *RedisCache) Get(key string) (string, error) {
+	return GetData(c.conn, key)
+}
+
+func (c *RedisCache) Set(key, val string, ttl time.Duration) error {
+	if _, err := SetData(c.conn, key, val, int(ttl/time.Second)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *RedisCache) Close() error {
+	return c.conn.Close()
+}
+
+// LRUCache is an in-process cache, safe for concurrent use, which evicts the least recently used keys beyond its size.
+type LRUCache struct {
+	mu sync.Mutex
+
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key       string
+	val       string
+	expiresAt time.Time
+}
+
+// NewLRUCache creates an in-process cache holding up to size keys.
+// It returns the LRU cache.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *LRUCache) Get(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return "", ErrCacheMiss
+	}
+	entry := el.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return "", ErrCacheMiss
+	}
+	c.order.MoveToFront(el)
+
+	return entry.val, nil
+}
+
+func (c *LRUCache) Set(key, val string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.val = val
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, val: val, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+
+	return nil
+}
+
+// Close keeps the entries, as the LRU cache is shared by the requests.
+func (c *LRUCache) Close() error {
+	return nil
+}
+
+// NoopCache never stores anything.
+type NoopCache struct{}
+
+func (NoopCache) Get(string) (string, error) {
+	return "", ErrCacheMiss
+}
+
+func (NoopCache) Set(string, string, time.Duration) error {
+	return nil
+}
+
+func (NoopCache) Close() error {
+	return nil
+}
@@ -0,0 +1,374 @@
+package utils
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"geomelody/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCache(t *testing.T) {
+	type entry struct {
+		key, val string
+		ttl      time.Duration
+	}
+
+	type vars struct {
+		size int
+		sets []entry
+		// gets are the keys read between the sets and the late sets, to make them the most recently used.
+		gets     []string
+		sleep    time.Duration
+		lateSets []entry
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   map[string]string
+		missed []string
+	}{
+		{
+			name: "should success to get the cached keys",
+			vars: vars{
+				size: 2,
+				sets: []entry{{key: "a", val: "1"}, {key: "b", val: "2"}},
+			},
+			want: map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name: "should evict the least recently set key beyond the size",
+			vars: vars{
+				size:     2,
+				sets:     []entry{{key: "a", val: "1"}, {key: "b", val: "2"}},
+				lateSets: []entry{{key: "c", val: "3"}},
+			},
+			want:   map[string]string{"b": "2", "c": "3"},
+			missed: []string{"a"},
+		},
+		{
+			name: "should evict the least recently read key beyond the size",
+			vars: vars{
+				size:     2,
+				sets:     []entry{{key: "a", val: "1"}, {key: "b", val: "2"}},
+				gets:     []string{"a"},
+				lateSets: []entry{{key: "c", val: "3"}},
+			},
+			want:   map[string]string{"a": "1", "c": "3"},
+			missed: []string{"b"},
+		},
+		{
+			name: "should overwrite a key without evicting another one",
+			vars: vars{
+				size:     2,
+				sets:     []entry{{key: "a", val: "1"}, {key: "b", val: "2"}},
+				lateSets: []entry{{key: "a", val: "10"}},
+			},
+			want: map[string]string{"a": "10", "b": "2"},
+		},
+		{
+			name: "should expire the keys once their TTL is over",
+			vars: vars{
+				size:  2,
+				sets:  []entry{{key: "a", val: "1", ttl: 10 * time.Millisecond}, {key: "b", val: "2"}},
+				sleep: 20 * time.Millisecond,
+			},
+			want:   map[string]string{"b": "2"},
+			missed: []string{"a"},
+		},
+		{
+			name: "should reset the TTL when the key is overwritten",
+			vars: vars{
+				size:     2,
+				sets:     []entry{{key: "a", val: "1", ttl: 10 * time.Millisecond}},
+				sleep:    20 * time.Millisecond,
+				lateSets: []entry{{key: "a", val: "2", ttl: time.Minute}},
+			},
+			want: map[string]string{"a": "2"},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			c := NewLRUCache(tCase.vars.size)
+			for _, e := range tCase.vars.sets {
+				_ = c.Set(e.key, e.val, e.ttl)
+			}
+			for _, key := range tCase.vars.gets {
+				_, _ = c.Get(key)
+			}
+			time.Sleep(tCase.vars.sleep)
+
+			// Run test
+			for _, e := range tCase.vars.lateSets {
+				_ = c.Set(e.key, e.val, e.ttl)
+			}
+
+			// Assert
+			for key, want := range tCase.want {
+				got, err := c.Get(key)
+				assert.NoErrorf(t, err, "case: %v, key: %v", tCase.name, key)
+				assert.Equalf(t, want, got, "case: %v, key: %v", tCase.name, key)
+			}
+			for _, key := range tCase.missed {
+				_, err := c.Get(key)
+				assert.ErrorIsf(t, err, ErrCacheMiss, "case: %v, key: %v", tCase.name, key)
+			}
+			assert.LessOrEqualf(t, c.order.Len(), tCase.vars.size, "case: %v", tCase.name)
+			assert.Equalf(t, c.order.Len(), len(c.entries), "case: %v", tCase.name)
+		})
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	type vars struct {
+		backend   string
+		fallback  string
+		redisDown bool
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   Cache
+		stores bool
+	}{
+		{
+			name:   "should success to open the Redis cache",
+			vars:   vars{backend: CacheRedis, fallback: CacheLRU},
+			want:   &RedisCache{},
+			stores: true,
+		},
+		{
+			name:   "should success to open the LRU cache",
+			vars:   vars{backend: CacheLRU, fallback: CacheNoop},
+			want:   &LRUCache{},
+			stores: true,
+		},
+		{
+			name: "should success to open the no-op cache",
+			vars: vars{backend: CacheNoop, fallback: CacheLRU},
+			want: NoopCache{},
+		},
+		{
+			name:   "should fall back to the LRU cache when Redis is down",
+			vars:   vars{backend: CacheRedis, fallback: CacheLRU, redisDown: true},
+			want:   &LRUCache{},
+			stores: true,
+		},
+		{
+			name: "should fall back to the no-op cache when Redis is down",
+			vars: vars{backend: CacheRedis, fallback: CacheNoop, redisDown: true},
+			want: NoopCache{},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			addr := startFakeRedis(t, &fakeRedis{role: "master"}, nil)
+			if tCase.vars.redisDown {
+				ln, _ := net.Listen("tcp", "127.0.0.1:0")
+				addr = ln.Addr().String()
+				_ = ln.Close()
+			}
+			useRedis(t, addr)
+			oldFallback := cacheFallback
+			defer func() {
+				cacheFallback = oldFallback
+			}()
+			cacheBackend, cacheFallback = tCase.vars.backend, tCase.vars.fallback
+
+			// Run test
+			got := NewCache()
+			defer func() {
+				_ = got.Close()
+			}()
+
+			// Assert
+			assert.IsTypef(t, tCase.want, got, "case: %v", tCase)
+			setErr := got.Set("top-track:"+tCase.name, "Kesariya", time.Minute)
+			val, getErr := got.Get("top-track:" + tCase.name)
+			assert.NoErrorf(t, setErr, "case: %v", tCase)
+			if tCase.stores {
+				assert.NoErrorf(t, getErr, "case: %v", tCase)
+				assert.Equalf(t, "Kesariya", val, "case: %v", tCase)
+			} else {
+				assert.ErrorIsf(t, getErr, ErrCacheMiss, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+// acceptCounter is a listener which counts the accepted connections.
+type acceptCounter struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *acceptCounter) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+
+	return conn, err
+}
+
+func TestNewCache_RedisCooldown(t *testing.T) {
+	// Setup
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	downAddr := ln.Addr().String()
+	_ = ln.Close()
+	useRedis(t, downAddr)
+	oldFallback := cacheFallback
+	defer func() {
+		cacheFallback = oldFallback
+	}()
+	cacheBackend, cacheFallback = CacheRedis, CacheLRU
+
+	// Run test
+	got := NewCache()
+
+	// Assert
+	assert.IsType(t, &LRUCache{}, got)
+
+	// Setup
+	ln, _ = net.Listen("tcp", "127.0.0.1:0")
+	counter := &acceptCounter{Listener: ln}
+	useRedis(t, startFakeRedis(t, &fakeRedis{role: "master"}, counter))
+	cacheBackend = CacheRedis
+
+	// Run test
+	got = NewCache()
+
+	// Assert
+	assert.IsType(t, &LRUCache{}, got)
+	assert.Equal(t, int32(0), counter.accepted.Load(), "Redis should not be dialed during the cooldown")
+
+	// Run test
+	cacheRedisMu.Lock()
+	cacheRedisDownUntil = time.Now().Add(-time.Millisecond)
+	cacheRedisMu.Unlock()
+	got = NewCache()
+	defer func() {
+		_ = got.Close()
+	}()
+
+	// Assert
+	assert.IsType(t, &RedisCache{}, got)
+	assert.Eventually(t, func() bool {
+		return counter.accepted.Load() == 1
+	}, time.Second, 10*time.Millisecond, "Redis should be dialed again once the cooldown is over")
+}
+
+func TestInitCache(t *testing.T) {
+	type vars struct {
+		backend  string
+		fallback string
+		lruSize  string
+		cooldown string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		wantBackend  string
+		wantFallback string
+		wantLRUSize  int
+		wantCooldown time.Duration
+		hasErr       bool
+		err          string
+	}{
+		{
+			name:         "should success to keep the defaults",
+			vars:         vars{},
+			wantBackend:  CacheRedis,
+			wantFallback: CacheLRU,
+			wantLRUSize:  1000,
+			wantCooldown: 5 * time.Second,
+		},
+		{
+			name:         "should success to read the backend, fallback, LRU size and Redis cooldown",
+			vars:         vars{backend: CacheLRU, fallback: CacheNoop, lruSize: "50", cooldown: "30s"},
+			wantBackend:  CacheLRU,
+			wantFallback: CacheNoop,
+			wantLRUSize:  50,
+			wantCooldown: 30 * time.Second,
+		},
+		{
+			name:   "should fail when the backend is unknown",
+			vars:   vars{backend: "memcached"},
+			hasErr: true,
+			err:    "unknown cache backend: memcached",
+		},
+		{
+			name:   "should fail when the fallback is unknown",
+			vars:   vars{fallback: CacheRedis},
+			hasErr: true,
+			err:    "unknown cache fallback: redis, expected lru or noop",
+		},
+		{
+			name:   "should fail when the LRU size is not a number",
+			vars:   vars{lruSize: "big"},
+			hasErr: true,
+			err:    "invalid CACHE_LRU_SIZE: big",
+		},
+		{
+			name:   "should fail when the LRU size is not positive",
+			vars:   vars{lruSize: "0"},
+			hasErr: true,
+			err:    "invalid CACHE_LRU_SIZE: 0",
+		},
+		{
+			name:   "should fail when the Redis cooldown is not a duration",
+			vars:   vars{cooldown: "5"},
+			hasErr: true,
+			err:    "invalid CACHE_REDIS_COOLDOWN: 5",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			oldBackend, oldFallback, oldSize, oldCooldown := cacheBackend, cacheFallback, lruSize, cacheRedisCooldown
+			defer func() {
+				cacheBackend, cacheFallback, lruSize, cacheRedisCooldown = oldBackend, oldFallback, oldSize, oldCooldown
+				constants.CACHE_BACKEND, constants.CACHE_FALLBACK, constants.CACHE_LRU_SIZE = "", "", ""
+				constants.CACHE_REDIS_COOLDOWN = ""
+			}()
+			cacheBackend, cacheFallback, lruSize, cacheRedisCooldown = CacheRedis, CacheLRU, 1000, 5*time.Second
+			constants.CACHE_BACKEND = tCase.vars.backend
+			constants.CACHE_FALLBACK = tCase.vars.fallback
+			constants.CACHE_LRU_SIZE = tCase.vars.lruSize
+			constants.CACHE_REDIS_COOLDOWN = tCase.vars.cooldown
+
+			// Run test
+			err := InitCache()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+				return
+			}
+			assert.NoErrorf(t, err, "case: %v", tCase)
+			assert.Equalf(t, tCase.wantBackend, cacheBackend, "case: %v", tCase)
+			assert.Equalf(t, tCase.wantFallback, cacheFallback, "case: %v", tCase)
+			assert.Equalf(t, tCase.wantLRUSize, lruSize, "case: %v", tCase)
+			assert.Equalf(t, tCase.wantCooldown, cacheRedisCooldown, "case: %v", tCase)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type VendorErrorKind string
+
+const (
+	VendorInvalidParameter VendorErrorKind = "invalid parameter"
+	VendorNotFound         VendorErrorKind = "not found"
+	VendorRateLimited      VendorErrorKind = "rate limited"
+	VendorAuthFailed       VendorErrorKind = "auth failed"
+	VendorQuotaExceeded    VendorErrorKind = "quota exceeded"
+	VendorUnavailable      VendorErrorKind = "unavailable"
+)
+
+// VendorError is returned when a vendor API reports a failure, either through the HTTP status or through the error
+// code of its payload.
+type VendorError struct {
+	API     string
+	Kind    VendorErrorKind
+	Code    int
+	Message string
+}
+
+func (e *VendorError) Error() string {
+	return fmt.Sprintf("%v vendor API error, %v (code %v): %v", e.API, e.Kind, e.Code, e.Message)
+}
+
+// vendorErrorPayload holds the error fields of the vendor payloads.
+// LAST API sends {"error": 6, "message": "..."} and MUSIC MIX API sends {"message": {"header": {"status_code": 404}}}.
+type vendorErrorPayload struct {
+	Error   *int            `json:"error"`
+	Errors  interface{}     `json:"errors"`
+	Message json.RawMessage `json:"message"`
+}
+
+type musicMixErrorMessage struct {
+	Header struct {
+		StatusCode int    `json:"status_code"`
+		Hint       string `json:"hint"`
+	} `json:"header"`
+}
+
+// ClassifyVendorError is used to detect the failure reported by the given vendor API response.
+// It returns VendorError or nil if the response is a success.
+func ClassifyVendorError(api string, re *ExternalJSONResponse) error {
+	var payload vendorErrorPayload
+	if err := json.Unmarshal(re.Body, &payload); err != nil {
+		payload = vendorErrorPayload{}
+	}
+
+	if payload.Error != nil {
+		var message string
+		_ = json.Unmarshal(payload.Message, &message)
+
+		return &VendorError{
+			API:     api,
+			Kind:    lastFMErrorKind(*payload.Error, message),
+			Code:    *payload.Error,
+			Message: message,
+		}
+	}
+
+	var mm musicMixErrorMessage
+	if err := json.Unmarshal(payload.Message, &mm); err == nil {
+		if code := mm.Header.StatusCode; code != 0 && (code < 200 || code > 299) {
+			message := mm.Header.Hint
+			if message == "" {
+				message = http.StatusText(code)
+			}
+
+			return &VendorError{
+				API:     api,
+				Kind:    statusErrorKind(code),
+				Code:    code,
+				Message: message,
+			}
+		}
+	}
+
+	if re.StatusCode < 200 || re.StatusCode > 299 {
+		message := re.StrResponse
+		if payload.Errors != nil {
+			message = fmt.Sprintf("%v", payload.Errors)
+		}
+
+		return &VendorError{
+			API:     api,
+			Kind:    statusErrorKind(re.StatusCode),
+			Code:    re.StatusCode,
+			Message: message,
+		}
+	}
+
+	return nil
+}
+
+// lastFMErrorKind maps the LAST API error codes to vendor error kinds.
+// LAST API reports the unknown artists and tracks as invalid parameters, so they are told apart by the message.
+// It returns vendor error kind.
+func lastFMErrorKind(code int, message string) VendorErrorKind {
+	switch code {
+	case 6:
+		if message = strings.ToLower(message); strings.Contains(message, "not found") || strings.Contains(message, "could not be found") {
+			return VendorNotFound
+		}
+
+		return VendorInvalidParameter
+	case 7:
+		return VendorNotFound
+	case 4, 9, 10, 13, 14, 15, 26:
+		return VendorAuthFailed
+	case 29:
+		return VendorRateLimited
+	case 2, 3, 5:
+		return VendorInvalidParameter
+	}
+
+	return VendorUnavailable
+}
+
+// statusErrorKind maps the HTTP statuses, which MUSIC MIX API also uses as the status code of its payload header.
+// It returns vendor error kind.
+func statusErrorKind(code int) VendorErrorKind {
+	switch code {
+	case http.StatusBadRequest, http.StatusMethodNotAllowed:
+		return VendorInvalidParameter
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return VendorAuthFailed
+	case http.StatusPaymentRequired:
+		return VendorQuotaExceeded
+	case http.StatusNotFound:
+		return VendorNotFound
+	case http.StatusTooManyRequests:
+		return VendorRateLimited
+	}
+
+	return VendorUnavailable
+}
+
+// StatusForError is used to pick the HTTP status to respond with for the given vendor error.
+// It returns 400, 404 or 429 for the errors the client can act upon, 429 as well when the vendor rate limit is reached,
+// 503 for the calls short-circuited by an open breaker, 502 for the other vendor failures and 500 for the errors
+// which are not vendor errors.
+func StatusForError(err error) int {
+	var vendorErr *VendorError
+	var decodeErr *DecodeError
+	var circuitErr *CircuitOpenError
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return http.StatusTooManyRequests
+	} else if errors.As(err, &circuitErr) {
+		return http.StatusServiceUnavailable
+	} else if errors.As(err, &vendorErr) {
+		switch vendorErr.Kind {
+		case VendorInvalidParameter:
+			return http.StatusBadRequest
+		case VendorNotFound:
+			return http.StatusNotFound
+		case VendorRateLimited:
+			return http.StatusTooManyRequests
+		}
+
+		return http.StatusBadGateway
+	} else if errors.As(err, &decodeErr) {
+		return http.StatusBadGateway
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyVendorError(t *testing.T) {
+	type vars struct {
+		status int
+		body   string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want *VendorError
+	}{
+		{
+			name: "should success when the response is a success",
+			vars: vars{status: http.StatusOK, body: `{"tracks":{"track":[]}}`},
+		},
+		{
+			name: "should success when the MUSIC MIX API header is a success",
+			vars: vars{status: http.StatusOK, body: `{"message":{"header":{"status_code":200},"body":{}}}`},
+		},
+		{
+			name: "should classify the LAST API error 2 as an invalid parameter",
+			vars: vars{status: http.StatusOK, body: `{"error":2,"message":"Invalid service - This service does not exist"}`},
+			want: &VendorError{Kind: VendorInvalidParameter, Code: 2, Message: "Invalid service - This service does not exist"},
+		},
+		{
+			name: "should classify the LAST API error 3 as an invalid parameter",
+			vars: vars{status: http.StatusOK, body: `{"error":3,"message":"Invalid Method - No method with that name in this package"}`},
+			want: &VendorError{Kind: VendorInvalidParameter, Code: 3, Message: "Invalid Method - No method with that name in this package"},
+		},
+		{
+			name: "should classify the LAST API error 4 as an auth failure",
+			vars: vars{status: http.StatusForbidden, body: `{"error":4,"message":"Authentication Failed"}`},
+			want: &VendorError{Kind: VendorAuthFailed, Code: 4, Message: "Authentication Failed"},
+		},
+		{
+			name: "should classify the LAST API error 6 as an invalid parameter",
+			vars: vars{status: http.StatusOK, body: `{"error":6,"message":"country param invalid"}`},
+			want: &VendorError{Kind: VendorInvalidParameter, Code: 6, Message: "country param invalid"},
+		},
+		{
+			name: "should classify the LAST API error 6 of an unknown artist as not found",
+			vars: vars{status: http.StatusOK, body: `{"error":6,"message":"The artist you supplied could not be found","links":[]}`},
+			want: &VendorError{Kind: VendorNotFound, Code: 6, Message: "The artist you supplied could not be found"},
+		},
+		{
+			name: "should classify the LAST API error 7 as not found",
+			vars: vars{status: http.StatusOK, body: `{"error":7,"message":"Invalid resource specified"}`},
+			want: &VendorError{Kind: VendorNotFound, Code: 7, Message: "Invalid resource specified"},
+		},
+		{
+			name: "should classify the LAST API error 9 as an auth failure",
+			vars: vars{status: http.StatusOK, body: `{"error":9,"message":"Invalid session key - Please re-authenticate"}`},
+			want: &VendorError{Kind: VendorAuthFailed, Code: 9, Message: "Invalid session key - Please re-authenticate"},
+		},
+		{
+			name: "should classify the LAST API error 10 as an auth failure",
+			vars: vars{status: http.StatusForbidden, body: `{"error":10,"message":"Invalid API key - You must be granted a valid key by last.fm"}`},
+			want: &VendorError{Kind: VendorAuthFailed, Code: 10, Message: "Invalid API key - You must be granted a valid key by last.fm"},
+		},
+		{
+			name: "should classify the LAST API error 13 as an auth failure",
+			vars: vars{status: http.StatusOK, body: `{"error":13,"message":"Invalid method signature supplied"}`},
+			want: &VendorError{Kind: VendorAuthFailed, Code: 13, Message: "Invalid method signature supplied"},
+		},
+		{
+			name: "should classify the LAST API error 14 as an auth failure",
+			vars: vars{status: http.StatusOK, body: `{"error":14,"message":"This token has not been authorized"}`},
+			want: &VendorError{Kind: VendorAuthFailed, Code: 14, Message: "This token has not been authorized"},
+		},
+		{
+			name: "should classify the LAST API error 15 as an auth failure",
+			vars: vars{status: http.StatusOK, body: `{"error":15,"message":"This token has expired"}`},
+			want: &VendorError{Kind: VendorAuthFailed, Code: 15, Message: "This token has expired"},
+		},
+		{
+			name: "should classify the LAST API error 26 as an auth failure",
+			vars: vars{status: http.StatusOK, body: `{"error":26,"message":"Suspended API key - Access for your account has been suspended"}`},
+			want: &VendorError{Kind: VendorAuthFailed, Code: 26, Message: "Suspended API key - Access for your account has been suspended"},
+		},
+		{
+			name: "should classify the LAST API error 29 as rate limited",
+			vars: vars{status: http.StatusTooManyRequests, body: `{"error":29,"message":"Rate Limit Exceeded"}`},
+			want: &VendorError{Kind: VendorRateLimited, Code: 29, Message: "Rate Limit Exceeded"},
+		},
+		{
+			name: "should classify an unknown LAST API error as unavailable",
+			vars: vars{status: http.StatusOK, body: `{"error":16,"message":"There was a temporary error processing your request"}`},
+			want: &VendorError{Kind: VendorUnavailable, Code: 16, Message: "There was a temporary error processing your request"},
+		},
+		{
+			name: "should classify the MUSIC MIX API status 401 sent with HTTP 200 as an auth failure",
+			vars: vars{status: http.StatusOK, body: `{"message":{"header":{"status_code":401,"hint":"renew api key"},"body":[]}}`},
+			want: &VendorError{Kind: VendorAuthFailed, Code: 401, Message: "renew api key"},
+		},
+		{
+			name: "should classify the MUSIC MIX API status 402 sent with HTTP 200 as quota exceeded",
+			vars: vars{status: http.StatusOK, body: `{"message":{"header":{"status_code":402,"execute_time":0.0012},"body":[]}}`},
+			want: &VendorError{Kind: VendorQuotaExceeded, Code: 402, Message: "Payment Required"},
+		},
+		{
+			name: "should classify the MUSIC MIX API status 404 sent with HTTP 200 as not found",
+			vars: vars{status: http.StatusOK, body: `{"message":{"header":{"status_code":404},"body":[]}}`},
+			want: &VendorError{Kind: VendorNotFound, Code: 404, Message: "Not Found"},
+		},
+		{
+			name: "should classify the HTTP status 400 as an invalid parameter",
+			vars: vars{status: http.StatusBadRequest, body: `{"errors":"some error"}`},
+			want: &VendorError{Kind: VendorInvalidParameter, Code: 400, Message: "some error"},
+		},
+		{
+			name: "should classify the HTTP status 403 as an auth failure",
+			vars: vars{status: http.StatusForbidden, body: `forbidden`},
+			want: &VendorError{Kind: VendorAuthFailed, Code: 403, Message: "forbidden"},
+		},
+		{
+			name: "should classify the HTTP status 404 as not found",
+			vars: vars{status: http.StatusNotFound, body: `not found`},
+			want: &VendorError{Kind: VendorNotFound, Code: 404, Message: "not found"},
+		},
+		{
+			name: "should classify the HTTP status 429 as rate limited",
+			vars: vars{status: http.StatusTooManyRequests, body: `slow down`},
+			want: &VendorError{Kind: VendorRateLimited, Code: 429, Message: "slow down"},
+		},
+		{
+			name: "should classify the HTTP status 503 as unavailable",
+			vars: vars{status: http.StatusServiceUnavailable, body: `<html>down</html>`},
+			want: &VendorError{Kind: VendorUnavailable, Code: 503, Message: "<html>down</html>"},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			re := &ExternalJSONResponse{
+				StrResponse: tCase.vars.body,
+				Body:        []byte(tCase.vars.body),
+			}
+			re.StatusCode = tCase.vars.status
+
+			// Run test
+			err := ClassifyVendorError("TestAPI", re)
+
+			// Assert
+			if tCase.want == nil {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+			} else {
+				tCase.want.API = "TestAPI"
+				assert.Equalf(t, tCase.want, err, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+// TestLastFMErrorKind_NotFoundMessages pins the LAST API messages which tell an unknown artist or track apart from an
+// invalid parameter, as both are sent with the error 6. A wording change at LAST API must fail this test.
+func TestLastFMErrorKind_NotFoundMessages(t *testing.T) {
+	testCases := []struct {
+		message string
+		want    VendorErrorKind
+	}{
+		{message: "The artist you supplied could not be found", want: VendorNotFound},
+		{message: "Track not found", want: VendorNotFound},
+		{message: "Album not found", want: VendorNotFound},
+		{message: "Tag not found", want: VendorNotFound},
+		{message: "country param invalid", want: VendorInvalidParameter},
+		{message: "Invalid parameters - Your request is missing a required parameter", want: VendorInvalidParameter},
+		{message: "", want: VendorInvalidParameter},
+	}
+
+	for _, tCase := range testCases {
+		assert.Equalf(t, tCase.want, lastFMErrorKind(6, tCase.message), "case: %v", tCase)
+	}
+}
+
+func TestStatusForError(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		err error
+
+		want int
+	}{
+		{
+			name: "should respond 429 when the vendor rate limit is reached",
+			err:  &RateLimitError{Vendor: "LAST", Wait: time.Second},
+			want: http.StatusTooManyRequests,
+		},
+		{
+			name: "should respond 503 when the breaker is open",
+			err:  fmt.Errorf("wrapped: %w", &CircuitOpenError{Key: "GetTagInfo@ws.audioscrobbler.com", RetryIn: time.Second}),
+			want: http.StatusServiceUnavailable,
+		},
+		{
+			name: "should respond 400 for an invalid parameter",
+			err:  &VendorError{Kind: VendorInvalidParameter},
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "should respond 404 when not found",
+			err:  &VendorError{Kind: VendorNotFound},
+			want: http.StatusNotFound,
+		},
+		{
+			name: "should respond 429 when the vendor rate limits",
+			err:  &VendorError{Kind: VendorRateLimited},
+			want: http.StatusTooManyRequests,
+		},
+		{
+			name: "should respond 502 when the vendor auth fails",
+			err:  &VendorError{Kind: VendorAuthFailed},
+			want: http.StatusBadGateway,
+		},
+		{
+			name: "should respond 502 when the vendor quota is exceeded",
+			err:  &VendorError{Kind: VendorQuotaExceeded},
+			want: http.StatusBadGateway,
+		},
+		{
+			name: "should respond 502 when the vendor is unavailable",
+			err:  fmt.Errorf("wrapped: %w", &VendorError{Kind: VendorUnavailable}),
+			want: http.StatusBadGateway,
+		},
+		{
+			name: "should respond 502 when the vendor payload does not match the schema",
+			err:  &DecodeError{API: "TestAPI", Err: errors.New("unexpected end of JSON input")},
+			want: http.StatusBadGateway,
+		},
+		{
+			name: "should respond 500 for the other errors",
+			err:  errors.New("some error"),
+			want: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Run test
+			got := StatusForError(tCase.err)
+
+			// Assert
+			assert.Equalf(t, tCase.want, got, "case: %v", tCase)
+		})
+	}
+}
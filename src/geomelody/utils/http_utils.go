@@ -1,21 +1,21 @@
 package utils
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
-
-	"golang.org/x/net/http2"
 )
 
-var transport *http2.Transport
+var transport *http.Transport
 
 var httpClient *http.Client
 
@@ -40,12 +40,24 @@ type ExternalJSONResponse struct {
 	Response    map[string]interface{}
 	ArrResponse []interface{}
 	StrResponse string
+	Body        []byte
 	ExternalResponseAdditional
 }
 
 // GetAPIResponse calls an API.
 // It returns response of type interface and an error.
 func GetAPIResponse(reqCtx context.Context, name, url, method string, body map[string]interface{}, params, headers map[string]string) (interface{}, error) {
+	var data interface{}
+	if err := GetAPIResponseAs(reqCtx, name, url, method, body, params, headers, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetAPIResponseAs calls an API and decodes the response body into out, which should be a pointer to the typed vendor payload.
+// It returns DecodeError if the response body does not match the schema of out, else the API error if any.
+func GetAPIResponseAs(reqCtx context.Context, name, url, method string, body map[string]interface{}, params, headers map[string]string, out interface{}) error {
 	req := ExternalRequest{
 		Name:    name,
 		URL:     url,
@@ -60,14 +72,17 @@ func GetAPIResponse(reqCtx context.Context, name, url, method string, body map[s
 
 	var err error
 	if reqCtx, err = GetExternalAPIResponse(req, reqCtx); err != nil {
-		return nil, err
+		return err
 	}
 	respStr, _ := reqCtx.Value("api." + req.Name).(string)
-	var resp APIResponse
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
 	if err := json.Unmarshal([]byte(respStr), &resp); err != nil {
-		return nil, err
+		return err
 	}
-	return resp.Data, nil
+
+	return DecodeJSON(req.Name, resp.Data, out)
 }
 
 // GetExternalAPIResponse calls external api and adds the api response to the request context.
@@ -75,16 +90,12 @@ func GetAPIResponse(reqCtx context.Context, name, url, method string, body map[s
 func GetExternalAPIResponse(req ExternalRequest, reqCtx context.Context) (context.Context, error) {
 	req.ReqCtx = reqCtx
 
-	if resp, err := req.Do(); err != nil {
+	if re, err := req.doWithRetry(); err != nil {
 		return reqCtx, err
-	} else if re, err := ParseAsJSON(resp); err != nil {
-		return reqCtx, err
-	} else if re.StatusCode < 200 || re.StatusCode > 299 {
-		return reqCtx, errors.New(fmt.Sprintf("%v", re.Response["errors"]))
 	} else {
 		apiResp := APIResponse{
 			Code: re.StatusCode,
-			Data: re.Response,
+			Data: json.RawMessage(re.Body),
 		}
 		j, _ := json.Marshal(apiResp)
 		reqCtx = context.WithValue(reqCtx, "api."+req.Name, string(j))
@@ -93,18 +104,27 @@ func GetExternalAPIResponse(req ExternalRequest, reqCtx context.Context) (contex
 }
 
 // Do method execute the ExternalRequest.
+// The request is bound to ReqCtx, so that it is cancelled along with the client request or on its deadline.
 func (r *ExternalRequest) Do() (*http.Response, error) {
-	r.GetMockHeadersFromContext()
-	if _, ok := r.Headers["x-mock-api"]; ok {
+	if r.GetMockHeadersFromContext(); r.isMocked() {
 		return r.DoMock()
 	}
 
-	var resp *http.Response
+	ctx := r.ReqCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	method := r.Type
+	if method == "" {
+		method = http.MethodGet
+	}
+
 	body, err := r.getRequestBody()
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest(r.Type, r.URL, body)
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), r.URL, body)
 	if err != nil {
 		return nil, err
 	}
@@ -119,31 +139,47 @@ func (r *ExternalRequest) Do() (*http.Response, error) {
 	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err = httpClient.Get(req.URL.String())
-	if err != nil {
-		return nil, err
-	}
-
-	return resp, err
+	return httpClient.Do(req)
 }
 
-// getRequestBody checks the content type and returns the request body string accordingly.
-// It returns the request body string.
+// getRequestBody checks the content type and returns the request body accordingly.
+// RawBody is sent as it is whatever the content type, while Body is encoded as JSON or as a form.
+// It returns the request body reader, nil if the request has no body, and error.
 func (r *ExternalRequest) getRequestBody() (io.Reader, error) {
+	if r.RawBody != nil {
+		return bytes.NewReader(r.RawBody), nil
+	}
+	if r.Body == nil {
+		return nil, nil
+	}
+
 	var contentType string
 	for k, v := range r.Headers {
 		if strings.ToLower(k) == "content-type" {
-			contentType = strings.ToLower(v)
+			contentType = v
 			break
 		}
 	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("received invalid content type %q: %v", contentType, err)
+	}
 
-	switch contentType {
+	switch mediaType {
 	case "application/json":
-		j, _ := json.Marshal(r.Body)
-		return strings.NewReader(string(j)), nil
+		j, err := json.Marshal(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(j), nil
+	case "application/x-www-form-urlencoded":
+		form := url.Values{}
+		for k, v := range r.Body {
+			form.Set(k, fmt.Sprintf("%v", v))
+		}
+		return strings.NewReader(form.Encode()), nil
 	default:
-		return nil, errors.New("received unsupported content type")
+		return nil, fmt.Errorf("received unsupported content type %q, use RawBody to send it", mediaType)
 	}
 }
 
@@ -180,6 +216,7 @@ func ParseAsJSON(response *http.Response) (*ExternalJSONResponse, error) {
 		Response:    dResp,
 		ArrResponse: dArrResp,
 		StrResponse: dStrResp,
+		Body:        jResp,
 		ExternalResponseAdditional: ExternalResponseAdditional{
 			StatusCode: response.StatusCode,
 			Headers:    response.Header,
@@ -201,22 +238,8 @@ func updateDurationFromEnv(enVar string, rVar *time.Duration) {
 }
 
 func init() {
-	//var idleConnTimeout = 30 * time.Second
-	//var respHeaderTimeout = 5 * time.Second
-	//var maxIdleConnections = 100
-	//
-	//updateDurationFromEnv("HTTP_IDLE_CONN_TIMEOUT", &idleConnTimeout)
-	//updateDurationFromEnv("HTTP_RESPONSE_HEADER_TIMEOUT", &respHeaderTimeout)
-	//
-	//if val := os.Getenv("HTTP_MAX_IDLE_CONNS"); val != "" {
-	//	if i, err := strconv.Atoi(val); err == nil {
-	//		maxIdleConnections = i
-	//	} else {
-	//		log.Printf("Error parsing integer HTTP_MAX_IDLE_CONNS: %v", err)
-	//	}
-	//}
-
-	transport = &http2.Transport{}
+	// The env is not loaded yet, so the defaults are used until InitHTTPClient is called.
+	transport, _ = NewTransport(DefaultTransportConfig())
 
 	httpClient = &http.Client{Transport: transport}
 }
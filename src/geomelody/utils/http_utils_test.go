@@ -0,0 +1,294 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receivedRequest is what the test server got from the request.
+type receivedRequest struct {
+	method string
+	header http.Header
+	query  url.Values
+	body   string
+}
+
+func TestExternalRequest_Do(t *testing.T) {
+	received := make(chan receivedRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedRequest{
+			method: r.Method,
+			header: r.Header,
+			query:  r.URL.Query(),
+			body:   string(body),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type vars struct {
+		req ExternalRequest
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   receivedRequest
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to send a GET request with the headers and params",
+			vars: vars{
+				req: ExternalRequest{
+					Name:    "TestAPI",
+					URL:     server.URL + "/2.0/?format=json",
+					Headers: map[string]string{"X-Api-Key": "key"},
+					Params:  map[string]string{"method": "geo.gettoptracks", "country": "côte d'ivoire"},
+				},
+			},
+			want: receivedRequest{
+				method: http.MethodGet,
+				header: http.Header{"X-Api-Key": {"key"}},
+				query:  url.Values{"format": {"json"}, "method": {"geo.gettoptracks"}, "country": {"côte d'ivoire"}},
+			},
+		},
+		{
+			name: "should success to send a JSON body",
+			vars: vars{
+				req: ExternalRequest{
+					Name:    "TestAPI",
+					URL:     server.URL,
+					Type:    "post",
+					Headers: map[string]string{"Content-Type": "application/json; charset=utf-8"},
+					Body:    map[string]interface{}{"track": "Kesariya", "rank": 1},
+				},
+			},
+			want: receivedRequest{
+				method: http.MethodPost,
+				header: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+				query:  url.Values{},
+				body:   `{"rank":1,"track":"Kesariya"}`,
+			},
+		},
+		{
+			name: "should success to send a form encoded body",
+			vars: vars{
+				req: ExternalRequest{
+					Name:    "TestAPI",
+					URL:     server.URL,
+					Type:    http.MethodPost,
+					Headers: map[string]string{"content-type": "application/x-www-form-urlencoded"},
+					Body:    map[string]interface{}{"artist": "Simon & Garfunkel", "limit": 5},
+				},
+			},
+			want: receivedRequest{
+				method: http.MethodPost,
+				header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+				query:  url.Values{},
+				body:   "artist=Simon+%26+Garfunkel&limit=5",
+			},
+		},
+		{
+			name: "should success to send the raw body as it is whatever the content type",
+			vars: vars{
+				req: ExternalRequest{
+					Name:    "TestAPI",
+					URL:     server.URL,
+					Type:    http.MethodPut,
+					Headers: map[string]string{"Content-Type": "text/xml"},
+					Body:    map[string]interface{}{"ignored": true},
+					RawBody: []byte("<track>Kesariya</track>"),
+				},
+			},
+			want: receivedRequest{
+				method: http.MethodPut,
+				header: http.Header{"Content-Type": {"text/xml"}},
+				query:  url.Values{},
+				body:   "<track>Kesariya</track>",
+			},
+		},
+		{
+			name: "should fail when the content type is not supported",
+			vars: vars{
+				req: ExternalRequest{
+					Name:    "TestAPI",
+					URL:     server.URL,
+					Type:    http.MethodPost,
+					Headers: map[string]string{"Content-Type": "text/xml"},
+					Body:    map[string]interface{}{"track": "Kesariya"},
+				},
+			},
+			hasErr: true,
+			err:    `received unsupported content type "text/xml", use RawBody to send it`,
+		},
+		{
+			name: "should fail when the content type is missing",
+			vars: vars{
+				req: ExternalRequest{
+					Name: "TestAPI",
+					URL:  server.URL,
+					Type: http.MethodPost,
+					Body: map[string]interface{}{"track": "Kesariya"},
+				},
+			},
+			hasErr: true,
+			err:    `received invalid content type ""`,
+		},
+		{
+			name: "should fail when the request context is cancelled",
+			vars: vars{
+				req: ExternalRequest{
+					Name:   "TestAPI",
+					URL:    server.URL,
+					ReqCtx: cancelledCtx,
+				},
+			},
+			hasErr: true,
+			err:    context.Canceled.Error(),
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			req := tCase.vars.req
+			if req.ReqCtx == nil {
+				req.ReqCtx = context.Background()
+			}
+
+			// Run test
+			resp, err := req.Do()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+				assert.Lenf(t, received, 0, "case: %v", tCase)
+				return
+			}
+			if !assert.NoErrorf(t, err, "case: %v", tCase) {
+				return
+			}
+			re, err := ParseAsJSON(resp)
+			assert.NoErrorf(t, err, "case: %v", tCase)
+			assert.Equalf(t, http.StatusOK, re.StatusCode, "case: %v", tCase)
+			assert.Equalf(t, map[string]interface{}{"ok": true}, re.Response, "case: %v", tCase)
+
+			got := <-received
+			assert.Equalf(t, tCase.want.method, got.method, "case: %v", tCase)
+			for k, v := range tCase.want.header {
+				assert.Equalf(t, v, got.header.Values(k), "case: %v", tCase)
+			}
+			assert.Equalf(t, tCase.want.query, got.query, "case: %v", tCase)
+			if tCase.want.body != "" && json.Valid([]byte(tCase.want.body)) {
+				assert.JSONEqf(t, tCase.want.body, got.body, "case: %v", tCase)
+			} else {
+				assert.Equalf(t, tCase.want.body, got.body, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestExternalRequest_getRequestBody(t *testing.T) {
+	type vars struct {
+		req ExternalRequest
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   string
+		isNil  bool
+		hasErr bool
+		err    string
+	}{
+		{
+			name:  "should success without a body",
+			vars:  vars{req: ExternalRequest{Headers: map[string]string{"Content-Type": "application/json"}}},
+			isNil: true,
+		},
+		{
+			name: "should success to encode the body as JSON",
+			vars: vars{req: ExternalRequest{
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    map[string]interface{}{"tags": []string{"pop", "r&b"}},
+			}},
+			want: `{"tags":["pop","r&b"]}`,
+		},
+		{
+			name: "should success to encode the body as a form",
+			vars: vars{req: ExternalRequest{
+				Headers: map[string]string{"CONTENT-TYPE": "application/x-www-form-urlencoded; charset=utf-8"},
+				Body:    map[string]interface{}{"tag": "r&b"},
+			}},
+			want: "tag=r%26b",
+		},
+		{
+			name: "should success to send the raw body without content type",
+			vars: vars{req: ExternalRequest{RawBody: []byte("raw")}},
+			want: "raw",
+		},
+		{
+			name: "should fail when the body cannot be encoded as JSON",
+			vars: vars{req: ExternalRequest{
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    map[string]interface{}{"ch": make(chan int)},
+			}},
+			hasErr: true,
+			err:    "json: unsupported type: chan int",
+		},
+		{
+			name: "should fail when the content type is not supported",
+			vars: vars{req: ExternalRequest{
+				Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=x"},
+				Body:    map[string]interface{}{"track": "Kesariya"},
+			}},
+			hasErr: true,
+			err:    `received unsupported content type "multipart/form-data"`,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Run test
+			got, err := tCase.vars.req.getRequestBody()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+				return
+			}
+			assert.NoErrorf(t, err, "case: %v", tCase)
+			if tCase.isNil {
+				assert.Nilf(t, got, "case: %v", tCase)
+				return
+			}
+			b, _ := io.ReadAll(got)
+			if json.Valid([]byte(tCase.want)) {
+				assert.JSONEqf(t, tCase.want, string(b), "case: %v", tCase)
+			} else {
+				assert.Equalf(t, tCase.want, string(b), "case: %v", tCase)
+			}
+		})
+	}
+}
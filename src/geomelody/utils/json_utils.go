@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DecodeError is returned when a vendor API payload does not match the schema it is decoded into.
+type DecodeError struct {
+	API string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("error while decoding %v vendor API data: %v", e.API, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Validator is implemented by the vendor API payloads with required fields, as a typed decode zeroes the missing ones.
+type Validator interface {
+	Validate() error
+}
+
+// FlexInt is an integer which the vendors send either as a JSON number or as a numeric JSON string.
+type FlexInt int
+
+// UnmarshalJSON decodes a JSON number, a numeric string, an empty string or null into the integer.
+func (fi *FlexInt) UnmarshalJSON(b []byte) error {
+	var val interface{}
+	if err := json.Unmarshal(b, &val); err != nil {
+		return err
+	}
+
+	switch v := val.(type) {
+	case nil:
+		*fi = 0
+	case float64:
+		*fi = FlexInt(v)
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			*fi = 0
+		} else if i, err := strconv.Atoi(v); err == nil {
+			*fi = FlexInt(i)
+		} else if f, err := strconv.ParseFloat(v, 64); err == nil {
+			*fi = FlexInt(f)
+		} else {
+			return fmt.Errorf("cannot decode %q into an integer", v)
+		}
+	default:
+		return fmt.Errorf("cannot decode %s into an integer", b)
+	}
+
+	return nil
+}
+
+// Int is used to read the integer, which is 0 when the field was missing.
+// It returns the integer.
+func (fi *FlexInt) Int() int {
+	if fi == nil {
+		return 0
+	}
+
+	return int(*fi)
+}
+
+// DecodeJSON decodes the given vendor API payload into out, and validates its required fields if out is a Validator.
+// It returns DecodeError if the payload does not match the schema of out or misses one of its required fields.
+func DecodeJSON(api string, data []byte, out interface{}) error {
+	err := json.Unmarshal(data, out)
+	if v, ok := out.(Validator); ok && err == nil {
+		err = v.Validate()
+	}
+
+	if err != nil {
+		return &DecodeError{
+			API: api,
+			Err: err,
+		}
+	}
+
+	return nil
+}
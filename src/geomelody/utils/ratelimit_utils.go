@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"geomelody/constants"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// VendorRateLimit holds the token bucket of a vendor, refilled with Rate tokens per second up to Burst tokens.
+type VendorRateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitError is returned when no token of the vendor bucket could be taken before the deadline of the request.
+type RateLimitError struct {
+	Vendor string
+	Wait   time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v vendor rate limited, no request slot available within %v", e.Vendor, e.Wait.Round(time.Millisecond))
+}
+
+type vendorRateLimiter struct {
+	vendor  string
+	baseURL string
+	limit   VendorRateLimit
+}
+
+var (
+	rateLimitMu sync.RWMutex
+
+	// rateLimiters holds the token buckets of the vendors whose rate limit is set.
+	rateLimiters []vendorRateLimiter
+
+	// maxRateLimitWait is how long a request queues for a token, unless its deadline comes first.
+	maxRateLimitWait = 2 * time.Second
+
+	// rateLimitRedisCooldown is how long the calls are let through without asking Redis once it failed, so that a
+	// Redis outage does not add a connect timeout to every vendor call.
+	rateLimitRedisCooldown = 5 * time.Second
+
+	rateLimitRedisMu        sync.Mutex
+	rateLimitRedisDownUntil time.Time
+)
+
+// tokenBucketScript takes a token of the bucket stored in KEYS[1], refilled with ARGV[1] tokens per second up to ARGV[2].
+// The Redis clock is used, so that the replicas share the same bucket whatever their clock drift.
+// It returns 0 if a token was taken, else the milliseconds to wait for the next one.
+var tokenBucketScript = redis.NewScript(1, `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+
+return wait
+`)
+
+// SetVendorRateLimit sets the token bucket of the vendor whose API is served under the given base URL.
+// A zero rate removes the limit of the vendor.
+func SetVendorRateLimit(vendor, baseURL string, limit VendorRateLimit) {
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+
+	limiters := make([]vendorRateLimiter, 0, len(rateLimiters)+1)
+	for _, l := range rateLimiters {
+		if l.vendor != vendor {
+			limiters = append(limiters, l)
+		}
+	}
+	if limit.Rate > 0 && baseURL != "" {
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+		limiters = append(limiters, vendorRateLimiter{vendor: vendor, baseURL: baseURL, limit: limit})
+	}
+	rateLimiters = limiters
+}
+
+// InitVendorRateLimits reads the rate limits of LAST API and MUSIC MIX API from env.
+// The vendors are not limited unless their rate is set.
+func InitVendorRateLimits() {
+	updateDurationFromEnv("VENDOR_RATE_LIMIT_MAX_WAIT", &maxRateLimitWait)
+	updateDurationFromEnv("VENDOR_RATE_LIMIT_REDIS_COOLDOWN", &rateLimitRedisCooldown)
+
+	for _, v := range []struct {
+		vendor, baseURL, env string
+	}{
+		{vendor: "LAST", baseURL: constants.LAST_API_URL, env: "LAST_API"},
+		{vendor: "MUSIC MIX", baseURL: constants.MUSIC_MIX_URL, env: "MUSIC_MIX"},
+	} {
+		limit := VendorRateLimit{}
+		updateFloatFromEnv(v.env+"_RATE_LIMIT", &limit.Rate)
+		updateIntFromEnv(v.env+"_RATE_BURST", &limit.Burst)
+		SetVendorRateLimit(v.vendor, v.baseURL, limit)
+		if limit.Rate > 0 && cacheBackend != CacheRedis {
+			log.Printf("%v vendor rate limit is not enforced, as it is shared through Redis and the cache backend is %v", v.vendor, cacheBackend)
+		}
+	}
+}
+
+// vendorRateLimiterFor returns the token bucket of the vendor serving the given URL, if it is limited.
+func vendorRateLimiterFor(rawURL string) (vendorRateLimiter, bool) {
+	rateLimitMu.RLock()
+	defer rateLimitMu.RUnlock()
+
+	for _, l := range rateLimiters {
+		if strings.HasPrefix(rawURL, l.baseURL) {
+			return l, true
+		}
+	}
+
+	return vendorRateLimiter{}, false
+}
+
+// waitForVendorToken is used to take a token of the bucket of the vendor serving the given URL, which is shared by the
+// replicas through Redis. The request queues until a token is available, up to its deadline or maxRateLimitWait.
+// The vendors are not limited when Redis is not the cache backend, as there is then no Redis to share the bucket.
+// The calls are let through if Redis fails, as the limiter must not take the service down, and Redis is not asked
+// again until rateLimitRedisCooldown is over.
+// It returns RateLimitError if no token could be taken in time.
+func waitForVendorToken(ctx context.Context, rawURL string) error {
+	limiter, ok := vendorRateLimiterFor(rawURL)
+	if !ok || cacheBackend != CacheRedis || isRateLimitRedisDown() {
+		return nil
+	}
+
+	start := time.Now()
+	deadline := start.Add(maxRateLimitWait)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	conn, err := Conn()
+	if err != nil {
+		log.Printf("error connecting to Redis, %v vendor is not rate limited for %v: %v", limiter.vendor, rateLimitRedisCooldown, err)
+		markRateLimitRedisDown()
+		return nil
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	key := fmt.Sprintf("ratelimit:%v", strings.ReplaceAll(strings.ToLower(limiter.vendor), " ", ""))
+	for {
+		waitMs, err := redis.Int64(tokenBucketScript.Do(conn, key, limiter.limit.Rate, limiter.limit.Burst))
+		if err != nil {
+			log.Printf("error taking a %v vendor rate limit token, the calls are let through for %v: %v", limiter.vendor, rateLimitRedisCooldown, err)
+			markRateLimitRedisDown()
+			return nil
+		} else if waitMs <= 0 {
+			return nil
+		}
+
+		wait := time.Duration(waitMs) * time.Millisecond
+		if time.Now().Add(wait).After(deadline) {
+			return &RateLimitError{Vendor: limiter.vendor, Wait: deadline.Sub(start)}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isRateLimitRedisDown reports whether Redis failed less than rateLimitRedisCooldown ago.
+func isRateLimitRedisDown() bool {
+	rateLimitRedisMu.Lock()
+	defer rateLimitRedisMu.Unlock()
+
+	return time.Now().Before(rateLimitRedisDownUntil)
+}
+
+// markRateLimitRedisDown is used to let the calls through without asking Redis until rateLimitRedisCooldown is over.
+func markRateLimitRedisDown() {
+	rateLimitRedisMu.Lock()
+	defer rateLimitRedisMu.Unlock()
+
+	rateLimitRedisDownUntil = time.Now().Add(rateLimitRedisCooldown)
+}
@@ -0,0 +1,245 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// useRedis points the shared Redis pools to the given address and restores the rate limit and cache state once the test
+// is done.
+func useRedis(t *testing.T, addr string) {
+	cfg := testRedisConfig(RedisStandalone)
+	cfg.Host, cfg.Port, _ = net.SplitHostPort(addr)
+	cfg.ConnectTimeout = 100 * time.Millisecond
+	backend, _ := newRedisBackend(cfg)
+
+	redisMu.Lock()
+	oldRedis := sharedRedis
+	sharedRedis = backend
+	redisMu.Unlock()
+
+	rateLimitMu.RLock()
+	oldLimiters := rateLimiters
+	rateLimitMu.RUnlock()
+	oldBackend, oldMaxWait := cacheBackend, maxRateLimitWait
+
+	t.Cleanup(func() {
+		_ = backend.Close()
+		redisMu.Lock()
+		sharedRedis = oldRedis
+		redisMu.Unlock()
+
+		rateLimitMu.Lock()
+		rateLimiters = oldLimiters
+		rateLimitMu.Unlock()
+		cacheBackend, maxRateLimitWait = oldBackend, oldMaxWait
+
+		rateLimitRedisMu.Lock()
+		rateLimitRedisDownUntil = time.Time{}
+		rateLimitRedisMu.Unlock()
+
+		cacheRedisMu.Lock()
+		cacheRedisDownUntil = time.Time{}
+		cacheRedisMu.Unlock()
+	})
+}
+
+func TestWaitForVendorToken(t *testing.T) {
+	const baseURL = "https://ws.audioscrobbler.com/2.0/"
+
+	type vars struct {
+		url          string
+		cacheBackend string
+		redisDown    bool
+		waits        []int64
+		evalErr      string
+		timeout      time.Duration
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		evals      int
+		redisDown  bool
+		hasErr     bool
+		err        error
+		maxWait    time.Duration
+		minElapsed time.Duration
+	}{
+		{
+			name:  "should success without asking Redis when the vendor is not limited",
+			vars:  vars{url: "https://api.musixmatch.com/ws/1.1/", cacheBackend: CacheRedis, waits: []int64{5000}},
+			evals: 0,
+		},
+		{
+			name:  "should success without asking Redis when the cache backend is not Redis",
+			vars:  vars{url: baseURL, cacheBackend: CacheLRU, waits: []int64{5000}},
+			evals: 0,
+		},
+		{
+			name:  "should success to take an available token",
+			vars:  vars{url: baseURL, cacheBackend: CacheRedis, waits: []int64{0}},
+			evals: 1,
+		},
+		{
+			name:       "should success to queue until a token is available",
+			vars:       vars{url: baseURL, cacheBackend: CacheRedis, waits: []int64{30, 30, 0}},
+			evals:      3,
+			minElapsed: 60 * time.Millisecond,
+		},
+		{
+			name:    "should fail when no token is available before the max wait",
+			vars:    vars{url: baseURL, cacheBackend: CacheRedis, waits: []int64{5000}},
+			evals:   1,
+			hasErr:  true,
+			err:     &RateLimitError{Vendor: "LAST"},
+			maxWait: 200 * time.Millisecond,
+		},
+		{
+			name:    "should fail when no token is available before the request deadline",
+			vars:    vars{url: baseURL, cacheBackend: CacheRedis, waits: []int64{80}, timeout: 50 * time.Millisecond},
+			evals:   1,
+			hasErr:  true,
+			err:     &RateLimitError{Vendor: "LAST"},
+			maxWait: 50 * time.Millisecond,
+		},
+		{
+			name:      "should success to let the call through and cool down when Redis is down",
+			vars:      vars{url: baseURL, cacheBackend: CacheRedis, redisDown: true},
+			evals:     0,
+			redisDown: true,
+		},
+		{
+			name:      "should success to let the call through and cool down when the script fails",
+			vars:      vars{url: baseURL, cacheBackend: CacheRedis, evalErr: "ERR Error running script"},
+			evals:     1,
+			redisDown: true,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			f := &fakeRedis{role: "master", waits: tCase.vars.waits, evalErr: tCase.vars.evalErr}
+			addr := startFakeRedis(t, f, nil)
+			if tCase.vars.redisDown {
+				ln, _ := net.Listen("tcp", "127.0.0.1:0")
+				addr = ln.Addr().String()
+				_ = ln.Close()
+			}
+			useRedis(t, addr)
+			cacheBackend = tCase.vars.cacheBackend
+			maxRateLimitWait = 200 * time.Millisecond
+			SetVendorRateLimit("LAST", baseURL, VendorRateLimit{Rate: 10, Burst: 1})
+
+			ctx := context.Background()
+			if tCase.vars.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tCase.vars.timeout)
+				defer cancel()
+			}
+			start := time.Now()
+
+			// Run test
+			err := waitForVendorToken(ctx, tCase.vars.url)
+
+			// Assert
+			elapsed := time.Since(start)
+			if tCase.hasErr {
+				var rateLimitErr *RateLimitError
+				if assert.Truef(t, errors.As(err, &rateLimitErr), "case: %v, err: %v", tCase.name, err) {
+					assert.Equalf(t, tCase.err.(*RateLimitError).Vendor, rateLimitErr.Vendor, "case: %v", tCase.name)
+					assert.LessOrEqualf(t, rateLimitErr.Wait, tCase.maxWait, "case: %v", tCase.name)
+				}
+				assert.Lessf(t, elapsed, 100*time.Millisecond, "case: %v, should fail without waiting", tCase.name)
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase.name)
+				assert.GreaterOrEqualf(t, elapsed, tCase.minElapsed, "case: %v", tCase.name)
+			}
+			f.mu.Lock()
+			assert.Equalf(t, tCase.evals, f.evals, "case: %v", tCase.name)
+			f.mu.Unlock()
+			assert.Equalf(t, tCase.redisDown, isRateLimitRedisDown(), "case: %v", tCase.name)
+		})
+	}
+}
+
+func TestWaitForVendorToken_RedisCooldown(t *testing.T) {
+	// Setup
+	const baseURL = "https://ws.audioscrobbler.com/2.0/"
+	f := &fakeRedis{role: "master", waits: []int64{0}}
+	useRedis(t, startFakeRedis(t, f, nil))
+	cacheBackend = CacheRedis
+	SetVendorRateLimit("LAST", baseURL, VendorRateLimit{Rate: 10, Burst: 1})
+	markRateLimitRedisDown()
+
+	// Run test
+	err := waitForVendorToken(context.Background(), baseURL)
+
+	// Assert
+	assert.NoError(t, err)
+	f.mu.Lock()
+	assert.Equal(t, 0, f.evals, "Redis should not be asked during the cooldown")
+	f.mu.Unlock()
+
+	// Run test
+	rateLimitRedisMu.Lock()
+	rateLimitRedisDownUntil = time.Now().Add(-time.Millisecond)
+	rateLimitRedisMu.Unlock()
+	err = waitForVendorToken(context.Background(), baseURL)
+
+	// Assert
+	assert.NoError(t, err)
+	f.mu.Lock()
+	assert.Equal(t, 1, f.evals, "Redis should be asked again once the cooldown is over")
+	f.mu.Unlock()
+}
+
+// TestTokenBucketScript runs the token bucket script against the Redis server of REDIS_TEST_ADDR, as the fake server
+// does not run Lua.
+func TestTokenBucketScript(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+
+	// Setup
+	conn, err := redis.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	key := "ratelimit:test-" + time.Now().Format("150405.000000000")
+	defer func() {
+		_, _ = conn.Do("DEL", key)
+	}()
+	take := func() int64 {
+		wait, err := redis.Int64(tokenBucketScript.Do(conn, key, 10, 2))
+		assert.NoError(t, err)
+		return wait
+	}
+
+	// Run test & Assert
+	assert.Equal(t, int64(0), take(), "the bucket should start full")
+	assert.Equal(t, int64(0), take(), "the burst should be available at once")
+	wait := take()
+	assert.True(t, wait > 0 && wait <= 100, "the next token should come within 1/rate, got %vms", wait)
+
+	time.Sleep(time.Duration(wait) * time.Millisecond)
+	assert.Equal(t, int64(0), take(), "the token should be refilled after the wait")
+
+	ttl, err := redis.Int64(conn.Do("PTTL", key))
+	assert.NoError(t, err)
+	assert.True(t, ttl > 0 && ttl <= 1200, "the bucket should expire once refilled, got %vms", ttl)
+}
@@ -0,0 +1,331 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	clusterSlots        = 16384
+	clusterMaxRedirects = 3
+)
+
+// errClusterPipeline is returned by the pipelining methods of the cluster connections, as every command may go to
+// another node.
+var errClusterPipeline = errors.New("pipelining is not supported on Redis Cluster connections")
+
+// clusterBackend routes every command to the node owning the slot of its key, with a pool per node.
+// The slots are loaded from the seed nodes and updated on MOVED redirections.
+type clusterBackend struct {
+	cfg RedisConfig
+
+	mu    sync.RWMutex
+	slots []string
+	pools map[string]*redis.Pool
+}
+
+func newClusterBackend(cfg RedisConfig) *clusterBackend {
+	b := &clusterBackend{
+		cfg:   cfg,
+		slots: make([]string, clusterSlots),
+		pools: make(map[string]*redis.Pool),
+	}
+	if err := b.refreshSlots(); err != nil {
+		log.Printf("error loading Redis Cluster slots, they are loaded on first use: %v", err)
+	}
+
+	return b
+}
+
+// Get is used to hand out a cluster connection once a node answered PING, so that the callers fall back when the whole
+// cluster is unreachable.
+// It returns the connection and error if no seed or known node answered.
+func (b *clusterBackend) Get() (redis.Conn, error) {
+	if err := b.ping(); err != nil {
+		return nil, err
+	}
+
+	return &clusterConn{backend: b}, nil
+}
+
+// ping is used to PING the seed nodes, then the nodes known from the slots, until one of them answers.
+// It returns error if none answered.
+func (b *clusterBackend) ping() error {
+	addrs := append([]string{}, b.cfg.ClusterAddrs...)
+	b.mu.RLock()
+	for addr := range b.pools {
+		addrs = append(addrs, addr)
+	}
+	b.mu.RUnlock()
+
+	var lastErr error
+	tried := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		if tried[addr] {
+			continue
+		}
+		tried[addr] = true
+
+		conn := b.pool(addr).Get()
+		_, err := conn.Do("PING")
+		_ = conn.Close()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("no Redis Cluster node answered: %v", lastErr)
+}
+
+func (b *clusterBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var err error
+	for addr, pool := range b.pools {
+		if closeErr := pool.Close(); closeErr != nil {
+			err = closeErr
+		}
+		delete(b.pools, addr)
+	}
+
+	return err
+}
+
+// pool returns the pool of the given node, creating it if needed.
+func (b *clusterBackend) pool(addr string) *redis.Pool {
+	b.mu.RLock()
+	pool, ok := b.pools[addr]
+	b.mu.RUnlock()
+	if ok {
+		return pool
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if pool, ok = b.pools[addr]; !ok {
+		pool = b.cfg.newPool(func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, b.cfg.dialOptions()...)
+		})
+		b.pools[addr] = pool
+	}
+
+	return pool
+}
+
+// refreshSlots is used to load the owner of every slot from the first seed node which answers CLUSTER SLOTS.
+// It returns error if no seed node answered.
+func (b *clusterBackend) refreshSlots() error {
+	var lastErr error
+	for _, seed := range b.cfg.ClusterAddrs {
+		conn := b.pool(seed).Get()
+		reply, err := redis.Values(conn.Do("CLUSTER", "SLOTS"))
+		_ = conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		slots := make([]string, clusterSlots)
+		for _, r := range reply {
+			entry, err := redis.Values(r, nil)
+			if err != nil || len(entry) < 3 {
+				continue
+			}
+			start, _ := redis.Int(entry[0], nil)
+			end, _ := redis.Int(entry[1], nil)
+			master, err := redis.Values(entry[2], nil)
+			if err != nil || len(master) < 2 {
+				continue
+			}
+			host, _ := redis.String(master[0], nil)
+			port, _ := redis.Int(master[1], nil)
+			addr := net.JoinHostPort(host, strconv.Itoa(port))
+			for slot := start; slot <= end && slot < clusterSlots; slot++ {
+				slots[slot] = addr
+			}
+		}
+
+		b.mu.Lock()
+		b.slots = slots
+		b.mu.Unlock()
+
+		return nil
+	}
+
+	return fmt.Errorf("no Redis Cluster seed node answered: %v", lastErr)
+}
+
+// nodeFor returns the node owning the given slot, or the first seed node if the slots are not loaded.
+func (b *clusterBackend) nodeFor(slot int) string {
+	b.mu.RLock()
+	addr := b.slots[slot]
+	b.mu.RUnlock()
+
+	if addr == "" {
+		if err := b.refreshSlots(); err == nil {
+			b.mu.RLock()
+			addr = b.slots[slot]
+			b.mu.RUnlock()
+		}
+	}
+	if addr == "" {
+		addr = b.cfg.ClusterAddrs[0]
+	}
+
+	return addr
+}
+
+func (b *clusterBackend) setNode(slot int, addr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.slots[slot] = addr
+}
+
+// do is used to run the command on the node owning its key, following the MOVED and ASK redirections.
+// It returns the reply and error.
+func (b *clusterBackend) do(cmd string, args ...interface{}) (interface{}, error) {
+	slot := -1
+	addr := b.cfg.ClusterAddrs[0]
+	if key, ok := commandKey(cmd, args); ok {
+		slot = keySlot(key)
+		addr = b.nodeFor(slot)
+	}
+
+	asking := false
+	for redirects := 0; ; redirects++ {
+		conn := b.pool(addr).Get()
+		if asking {
+			_, _ = conn.Do("ASKING")
+		}
+		reply, err := conn.Do(cmd, args...)
+		_ = conn.Close()
+
+		var redisErr redis.Error
+		if !errors.As(err, &redisErr) || redirects >= clusterMaxRedirects {
+			return reply, err
+		}
+
+		// MOVED and ASK errors are "MOVED <slot> <addr>".
+		fields := strings.Fields(string(redisErr))
+		if len(fields) != 3 {
+			return reply, err
+		}
+		switch fields[0] {
+		case "MOVED":
+			addr, asking = fields[2], false
+			if slot >= 0 {
+				b.setNode(slot, addr)
+			}
+		case "ASK":
+			addr, asking = fields[2], true
+		default:
+			return reply, err
+		}
+	}
+}
+
+// commandKey returns the key a command operates on, which selects the node it is routed to.
+func commandKey(cmd string, args []interface{}) (string, bool) {
+	switch strings.ToUpper(cmd) {
+	case "", "PING", "ROLE", "CLUSTER", "ASKING", "INFO", "SCRIPT":
+		return "", false
+	case "EVAL", "EVALSHA":
+		// EVAL <script> <numkeys> <key> ...
+		if len(args) < 3 {
+			return "", false
+		}
+		if numKeys, err := strconv.Atoi(argString(args[1])); err != nil || numKeys < 1 {
+			return "", false
+		}
+		return argString(args[2]), true
+	}
+
+	if len(args) == 0 {
+		return "", false
+	}
+
+	return argString(args[0]), true
+}
+
+func argString(arg interface{}) string {
+	switch a := arg.(type) {
+	case string:
+		return a
+	case []byte:
+		return string(a)
+	}
+
+	return fmt.Sprint(arg)
+}
+
+// keySlot returns the cluster slot of the key, only the hash tag between braces is hashed if the key has one.
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	return int(crc16([]byte(key)) % clusterSlots)
+}
+
+// crc16 is the CRC16-CCITT (XMODEM) checksum used by Redis Cluster.
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
+
+// clusterConn is the connection handed out in cluster mode, every command borrowing a connection to its node.
+type clusterConn struct {
+	backend *clusterBackend
+}
+
+func (c *clusterConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if cmd == "" {
+		return nil, nil
+	}
+
+	return c.backend.do(cmd, args...)
+}
+
+func (c *clusterConn) Send(string, ...interface{}) error {
+	return errClusterPipeline
+}
+
+func (c *clusterConn) Flush() error {
+	return errClusterPipeline
+}
+
+func (c *clusterConn) Receive() (interface{}, error) {
+	return nil, errClusterPipeline
+}
+
+func (c *clusterConn) Err() error {
+	return nil
+}
+
+func (c *clusterConn) Close() error {
+	return nil
+}
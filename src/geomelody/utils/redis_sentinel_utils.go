@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// sentinelBackend hands out the connections of the master the sentinels point to.
+// Every new connection asks the sentinels for the master and checks its role. The idle connections check their role
+// again on borrow once HealthCheckInterval is over, and the connections answered READONLY are dropped, so that the
+// connections to a demoted master are replaced once the sentinels fail over.
+type sentinelBackend struct {
+	redisPoolBackend
+
+	cfg RedisConfig
+
+	mu         sync.Mutex
+	masterAddr string
+}
+
+func newSentinelBackend(cfg RedisConfig) *sentinelBackend {
+	b := &sentinelBackend{cfg: cfg}
+	b.pool = cfg.newPool(b.dial)
+	b.pool.TestOnBorrow = func(c redis.Conn, t time.Time) error {
+		if time.Since(t) < cfg.HealthCheckInterval {
+			return nil
+		}
+		return checkMasterRole(c)
+	}
+
+	return b
+}
+
+// dial is used to connect to the current master.
+// It returns the connection and error.
+func (b *sentinelBackend) dial() (redis.Conn, error) {
+	addr, err := b.discoverMaster()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := redis.Dial("tcp", addr, b.cfg.dialOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkMasterRole(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	b.mu.Lock()
+	if b.masterAddr != addr {
+		log.Printf("Redis master of %v is %v", b.cfg.SentinelMaster, addr)
+		b.masterAddr = addr
+	}
+	b.mu.Unlock()
+
+	return &masterConn{Conn: conn}, nil
+}
+
+// masterConn is a connection to the master, which is broken once the server answers READONLY, i.e. once it was
+// demoted to a replica, so that the pool drops it instead of handing it out again.
+type masterConn struct {
+	redis.Conn
+
+	mu  sync.Mutex
+	err error
+}
+
+func (c *masterConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	reply, err := c.Conn.Do(commandName, args...)
+	c.checkReadOnly(err)
+
+	return reply, err
+}
+
+func (c *masterConn) Receive() (interface{}, error) {
+	reply, err := c.Conn.Receive()
+	c.checkReadOnly(err)
+
+	return reply, err
+}
+
+func (c *masterConn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	return c.Conn.Err()
+}
+
+// checkReadOnly is used to break the connection if the given command error is a READONLY one.
+func (c *masterConn) checkReadOnly(err error) {
+	if redisErr, ok := err.(redis.Error); ok && strings.HasPrefix(string(redisErr), "READONLY") {
+		c.mu.Lock()
+		c.err = fmt.Errorf("redis server is no longer the master: %v", redisErr)
+		c.mu.Unlock()
+	}
+}
+
+// discoverMaster is used to ask the sentinels, in order, for the address of the master.
+// It returns the master address and error if no sentinel knows it.
+func (b *sentinelBackend) discoverMaster() (string, error) {
+	opts := []redis.DialOption{
+		redis.DialConnectTimeout(b.cfg.ConnectTimeout),
+		redis.DialReadTimeout(b.cfg.ReadTimeout),
+		redis.DialWriteTimeout(b.cfg.WriteTimeout),
+	}
+	if b.cfg.SentinelPassword != "" {
+		opts = append(opts, redis.DialPassword(b.cfg.SentinelPassword))
+	}
+
+	var lastErr error
+	for _, sentinelAddr := range b.cfg.SentinelAddrs {
+		conn, err := redis.Dial("tcp", sentinelAddr, opts...)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		reply, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", b.cfg.SentinelMaster))
+		_ = conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		} else if len(reply) != 2 {
+			lastErr = fmt.Errorf("sentinel %v does not know the master", sentinelAddr)
+			continue
+		}
+
+		return net.JoinHostPort(reply[0], reply[1]), nil
+	}
+
+	return "", fmt.Errorf("no sentinel knows the %v master: %v", b.cfg.SentinelMaster, lastErr)
+}
+
+// checkMasterRole is used to check that the connection is to a master, and not to a replica.
+// It returns error if the server is not a master.
+func checkMasterRole(c redis.Conn) error {
+	reply, err := redis.Values(c.Do("ROLE"))
+	if err != nil {
+		return err
+	} else if len(reply) == 0 {
+		return fmt.Errorf("received empty ROLE reply")
+	}
+
+	if role, _ := redis.String(reply[0], nil); role != "master" {
+		return fmt.Errorf("redis server is a %v, not the master", role)
+	}
+
+	return nil
+}
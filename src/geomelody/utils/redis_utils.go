@@ -3,19 +3,250 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"geomelody/constants"
 
 	"github.com/gomodule/redigo/redis"
 )
 
+const (
+	RedisStandalone = "standalone"
+	RedisSentinel   = "sentinel"
+	RedisCluster    = "cluster"
+)
+
+// RedisConfig holds the Redis deployment and the settings of the shared connection pools.
+type RedisConfig struct {
+	// Mode is standalone, sentinel or cluster.
+	Mode string
+
+	Host     string
+	Port     string
+	Password string
+	DB       int
+	TLS      bool
+
+	// SentinelAddrs are the sentinels asked for the address of the SentinelMaster master.
+	SentinelAddrs    []string
+	SentinelMaster   string
+	SentinelPassword string
+
+	// ClusterAddrs are the seed nodes the slots of the cluster are loaded from.
+	ClusterAddrs []string
+
+	MaxIdle     int
+	MaxActive   int
+	IdleTimeout time.Duration
+
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+
+	// HealthCheckInterval is how long a connection may stay idle before it is pinged on borrow.
+	HealthCheckInterval time.Duration
+}
+
+// redisBackend hands out the connections of a Redis deployment.
+type redisBackend interface {
+	Get() (redis.Conn, error)
+	Close() error
+}
+
+var (
+	redisMu     sync.Mutex
+	sharedRedis redisBackend
+)
+
+// RedisConfigFromEnv reads the Redis settings from the constants and env, keeping the defaults of the unset variables.
+// It returns redis config.
+func RedisConfigFromEnv() RedisConfig {
+	cfg := RedisConfig{
+		Mode:                RedisStandalone,
+		Host:                constants.REDIS_HOST,
+		Port:                constants.REDIS_PORT,
+		Password:            constants.REDIS_PASSWORD,
+		SentinelAddrs:       splitAddrs(constants.REDIS_SENTINEL_ADDRS),
+		SentinelMaster:      constants.REDIS_SENTINEL_MASTER,
+		SentinelPassword:    constants.REDIS_SENTINEL_PASSWORD,
+		ClusterAddrs:        splitAddrs(constants.REDIS_CLUSTER_ADDRS),
+		MaxIdle:             10,
+		MaxActive:           100,
+		IdleTimeout:         4 * time.Minute,
+		ConnectTimeout:      time.Second,
+		ReadTimeout:         time.Second,
+		WriteTimeout:        time.Second,
+		HealthCheckInterval: time.Minute,
+	}
+
+	if constants.REDIS_MODE != "" {
+		cfg.Mode = strings.ToLower(constants.REDIS_MODE)
+	}
+	if db, err := strconv.Atoi(constants.REDIS_DB); err == nil {
+		cfg.DB = db
+	} else if constants.REDIS_DB != "" {
+		log.Printf("Error parsing integer REDIS_DB: %v", err)
+	}
+	if useTLS, err := strconv.ParseBool(constants.REDIS_TLS); err == nil {
+		cfg.TLS = useTLS
+	} else if constants.REDIS_TLS != "" {
+		log.Printf("Error parsing boolean REDIS_TLS: %v", err)
+	}
+
+	updateIntFromEnv("REDIS_POOL_MAX_IDLE", &cfg.MaxIdle)
+	updateIntFromEnv("REDIS_POOL_MAX_ACTIVE", &cfg.MaxActive)
+	updateDurationFromEnv("REDIS_POOL_IDLE_TIMEOUT", &cfg.IdleTimeout)
+	updateDurationFromEnv("REDIS_CONNECT_TIMEOUT", &cfg.ConnectTimeout)
+	updateDurationFromEnv("REDIS_READ_TIMEOUT", &cfg.ReadTimeout)
+	updateDurationFromEnv("REDIS_WRITE_TIMEOUT", &cfg.WriteTimeout)
+	updateDurationFromEnv("REDIS_HEALTH_CHECK_INTERVAL", &cfg.HealthCheckInterval)
+
+	return cfg
+}
+
+// splitAddrs splits a comma separated list of host:port addresses.
+func splitAddrs(val string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(val, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs
+}
+
+// newRedisBackend creates the connection pools of the Redis deployment of the given config.
+// It returns redis backend and error if the config of its mode is incomplete.
+func newRedisBackend(cfg RedisConfig) (redisBackend, error) {
+	switch cfg.Mode {
+	case RedisStandalone, "":
+		addr := fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
+		return &redisPoolBackend{pool: cfg.newPool(func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, cfg.dialOptions()...)
+		})}, nil
+	case RedisSentinel:
+		if len(cfg.SentinelAddrs) == 0 || cfg.SentinelMaster == "" {
+			return nil, errors.New("REDIS_SENTINEL_ADDRS and REDIS_SENTINEL_MASTER are required in sentinel mode")
+		}
+		return newSentinelBackend(cfg), nil
+	case RedisCluster:
+		if len(cfg.ClusterAddrs) == 0 {
+			return nil, errors.New("REDIS_CLUSTER_ADDRS is required in cluster mode")
+		}
+		return newClusterBackend(cfg), nil
+	}
+
+	return nil, fmt.Errorf("unknown Redis mode: %v", cfg.Mode)
+}
+
+// newPool creates a pool of the connections made by dial.
+// The pool does not wait for a free connection once MaxActive is reached, the callers fall back instead.
+// It returns redis pool.
+func (cfg RedisConfig) newPool(dial func() (redis.Conn, error)) *redis.Pool {
+	return &redis.Pool{
+		MaxIdle:     cfg.MaxIdle,
+		MaxActive:   cfg.MaxActive,
+		IdleTimeout: cfg.IdleTimeout,
+		Dial:        dial,
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			if time.Since(t) < cfg.HealthCheckInterval {
+				return nil
+			}
+			_, err := c.Do("PING")
+			return err
+		},
+	}
+}
+
+func (cfg RedisConfig) dialOptions() []redis.DialOption {
+	opts := []redis.DialOption{
+		redis.DialConnectTimeout(cfg.ConnectTimeout),
+		redis.DialReadTimeout(cfg.ReadTimeout),
+		redis.DialWriteTimeout(cfg.WriteTimeout),
+		redis.DialUseTLS(cfg.TLS),
+	}
+	// Redis Cluster only has the database 0.
+	if cfg.Mode != RedisCluster {
+		opts = append(opts, redis.DialDatabase(cfg.DB))
+	}
+	if cfg.Password != "" {
+		opts = append(opts, redis.DialPassword(cfg.Password))
+	}
+
+	return opts
+}
+
+// redisPoolBackend hands out the connections of a single pool, to a standalone server or to the sentinel master.
+type redisPoolBackend struct {
+	pool *redis.Pool
+}
+
+func (b *redisPoolBackend) Get() (redis.Conn, error) {
+	conn := b.pool.Get()
+	if err := conn.Err(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (b *redisPoolBackend) Close() error {
+	return b.pool.Close()
+}
+
+// InitRedisPool creates the shared Redis pools from the env, so it must be called once the constants are loaded.
+// It returns error if the Redis mode or its addresses are invalid.
+func InitRedisPool() error {
+	cfg := RedisConfigFromEnv()
+	backend, err := newRedisBackend(cfg)
+	if err != nil {
+		return err
+	}
+
+	redisMu.Lock()
+	old := sharedRedis
+	sharedRedis = backend
+	redisMu.Unlock()
+
+	if old != nil {
+		_ = old.Close()
+	}
+	log.Printf("Initialized Redis pool, mode: %v, max idle: %v, max active: %v, TLS: %v", cfg.Mode, cfg.MaxIdle, cfg.MaxActive, cfg.TLS)
+
+	return nil
+}
+
+func getRedisBackend() (redisBackend, error) {
+	redisMu.Lock()
+	defer redisMu.Unlock()
+
+	if sharedRedis == nil {
+		backend, err := newRedisBackend(RedisConfigFromEnv())
+		if err != nil {
+			return nil, err
+		}
+		sharedRedis = backend
+	}
+
+	return sharedRedis, nil
+}
+
+// Conn borrows a connection of the shared Redis pools, closing it gives it back.
+// In cluster mode the connection routes every command to the node owning its key.
+// It returns the connection and error if no healthy connection could be made.
 func Conn() (redis.Conn, error) {
-	conn, err := redis.Dial("tcp", fmt.Sprintf("%s:%s", constants.REDIS_HOST, constants.REDIS_PORT))
+	backend, err := getRedisBackend()
 	if err != nil {
 		return nil, err
 	}
 
-	return conn, nil
+	return backend.Get()
 }
 
 func GetData(conn redis.Conn, key string) (string, error) {
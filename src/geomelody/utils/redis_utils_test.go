@@ -0,0 +1,831 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"geomelody/constants"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis is a stand-in for the Redis servers, sentinels and cluster nodes, speaking just enough RESP for the cache.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+	role string
+
+	// masterName and candidates make a sentinel, which points to the first reachable candidate and promotes it when the
+	// previous ones are down.
+	masterName string
+	candidates []string
+
+	// slots make a cluster node, which answers MOVED for the keys of the slots it does not own.
+	self  string
+	slots []fakeSlots
+
+	// waits are the milliseconds answered to the successive token bucket scripts, the last one being repeated, and
+	// evalErr the error answered instead when it is set. evals counts the scripts run.
+	waits   []int64
+	evalErr string
+	evals   int
+
+	// commands are the commands received, with their args.
+	commands []string
+}
+
+type fakeSlots struct {
+	start, end int
+	addr       string
+}
+
+func (f *fakeRedis) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte(f.reply(args)))
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+
+	return args, nil
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func (f *fakeRedis) reply(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.commands = append(f.commands, strings.Join(args, " "))
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "AUTH", "SELECT", "ASKING":
+		return "+OK\r\n"
+	case "ROLE":
+		return fmt.Sprintf("*1\r\n%v", bulk(f.role))
+	case "REPLICAOF":
+		f.role = "master"
+		return "+OK\r\n"
+	case "SENTINEL":
+		return f.sentinelReply(args)
+	case "CLUSTER":
+		res := fmt.Sprintf("*%d\r\n", len(f.slots))
+		for _, s := range f.slots {
+			host, port, _ := net.SplitHostPort(s.addr)
+			res += fmt.Sprintf("*3\r\n:%d\r\n:%d\r\n*2\r\n%v:%v\r\n", s.start, s.end, bulk(host), port)
+		}
+		return res
+	case "EVALSHA", "EVAL":
+		// EVALSHA <sha> <numkeys> <key> ...
+		if len(args) > 3 && args[2] != "0" {
+			if moved := f.movedReply(args[3]); moved != "" {
+				return moved
+			}
+		}
+		f.evals++
+		if f.evalErr != "" {
+			return fmt.Sprintf("-%v\r\n", f.evalErr)
+		}
+		var wait int64
+		if len(f.waits) > 0 {
+			wait = f.waits[0]
+		}
+		if len(f.waits) > 1 {
+			f.waits = f.waits[1:]
+		}
+		return fmt.Sprintf(":%d\r\n", wait)
+	case "GET", "SET":
+		if moved := f.movedReply(args[1]); moved != "" {
+			return moved
+		}
+		if strings.ToUpper(args[0]) == "GET" {
+			if val, ok := f.data[args[1]]; ok {
+				return bulk(val)
+			}
+			return "$-1\r\n"
+		} else if f.role != "master" {
+			return "-READONLY You can't write against a read only replica.\r\n"
+		}
+		f.data[args[1]] = args[2]
+		return "+OK\r\n"
+	}
+
+	return fmt.Sprintf("-ERR unknown command '%v'\r\n", args[0])
+}
+
+func (f *fakeRedis) sentinelReply(args []string) string {
+	if len(args) != 3 || args[2] != f.masterName {
+		return "*-1\r\n"
+	}
+
+	failedOver := false
+	for len(f.candidates) > 0 {
+		conn, err := redis.Dial("tcp", f.candidates[0], redis.DialConnectTimeout(100*time.Millisecond))
+		if err == nil && failedOver {
+			_, err = conn.Do("REPLICAOF", "NO", "ONE")
+		}
+		if conn != nil {
+			_ = conn.Close()
+		}
+		if err == nil {
+			host, port, _ := net.SplitHostPort(f.candidates[0])
+			return fmt.Sprintf("*2\r\n%v%v", bulk(host), bulk(port))
+		}
+		f.candidates = f.candidates[1:]
+		failedOver = true
+	}
+
+	return "*-1\r\n"
+}
+
+func (f *fakeRedis) movedReply(key string) string {
+	slot := keySlot(key)
+	for _, s := range f.slots {
+		if slot >= s.start && slot <= s.end && s.addr != f.self {
+			return fmt.Sprintf("-MOVED %d %v\r\n", slot, s.addr)
+		}
+	}
+
+	return ""
+}
+
+// startFakeRedis serves the fake server in this process.
+// It returns the address of the server.
+func startFakeRedis(t *testing.T, f *fakeRedis, ln net.Listener) string {
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.data = make(map[string]string)
+	go f.serve(ln)
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+
+	return ln.Addr().String()
+}
+
+// startFakeRedisProcess serves a fake server in a child process, so that it can be killed like a crashed server.
+// It returns the address of the server and its process.
+func startFakeRedisProcess(t *testing.T, role, masterName string, candidates ...string) (string, *exec.Cmd) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperFakeRedisProcess$")
+	cmd.Env = append(os.Environ(),
+		"GEOMELODY_FAKE_REDIS=1",
+		"GEOMELODY_FAKE_REDIS_ROLE="+role,
+		"GEOMELODY_FAKE_REDIS_MASTER="+masterName,
+		"GEOMELODY_FAKE_REDIS_CANDIDATES="+strings.Join(candidates, ","),
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(line, "ADDR ")), cmd
+}
+
+// TestHelperFakeRedisProcess is not a test, it is the fake server run by startFakeRedisProcess.
+func TestHelperFakeRedisProcess(t *testing.T) {
+	if os.Getenv("GEOMELODY_FAKE_REDIS") != "1" {
+		return
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		os.Exit(1)
+	}
+	f := &fakeRedis{
+		data:       make(map[string]string),
+		role:       os.Getenv("GEOMELODY_FAKE_REDIS_ROLE"),
+		masterName: os.Getenv("GEOMELODY_FAKE_REDIS_MASTER"),
+		candidates: splitAddrs(os.Getenv("GEOMELODY_FAKE_REDIS_CANDIDATES")),
+	}
+	fmt.Printf("ADDR %v\n", ln.Addr())
+	f.serve(ln)
+	os.Exit(0)
+}
+
+func testRedisConfig(mode string) RedisConfig {
+	return RedisConfig{
+		Mode:                mode,
+		MaxIdle:             2,
+		IdleTimeout:         time.Minute,
+		ConnectTimeout:      time.Second,
+		ReadTimeout:         time.Second,
+		WriteTimeout:        time.Second,
+		HealthCheckInterval: time.Minute,
+	}
+}
+
+func TestRedisConfigFromEnv(t *testing.T) {
+	type vars struct {
+		constants map[*string]string
+		env       map[string]string
+	}
+
+	defaults := RedisConfig{
+		Mode:                RedisStandalone,
+		Host:                "redis",
+		Port:                "6379",
+		MaxIdle:             10,
+		MaxActive:           100,
+		IdleTimeout:         4 * time.Minute,
+		ConnectTimeout:      time.Second,
+		ReadTimeout:         time.Second,
+		WriteTimeout:        time.Second,
+		HealthCheckInterval: time.Minute,
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want RedisConfig
+	}{
+		{
+			name: "should success to keep the defaults",
+			vars: vars{},
+			want: defaults,
+		},
+		{
+			name: "should success to read the settings from the constants and env",
+			vars: vars{
+				constants: map[*string]string{
+					&constants.REDIS_MODE:              "Sentinel",
+					&constants.REDIS_PASSWORD:          "secret",
+					&constants.REDIS_DB:                "3",
+					&constants.REDIS_TLS:               "true",
+					&constants.REDIS_SENTINEL_ADDRS:    " sentinel-1:26379, sentinel-2:26379 ,",
+					&constants.REDIS_SENTINEL_MASTER:   "geomelody",
+					&constants.REDIS_SENTINEL_PASSWORD: "sentinel-secret",
+				},
+				env: map[string]string{
+					"REDIS_POOL_MAX_IDLE":         "5",
+					"REDIS_POOL_MAX_ACTIVE":       "50",
+					"REDIS_POOL_IDLE_TIMEOUT":     "2m",
+					"REDIS_CONNECT_TIMEOUT":       "200ms",
+					"REDIS_READ_TIMEOUT":          "300ms",
+					"REDIS_WRITE_TIMEOUT":         "400ms",
+					"REDIS_HEALTH_CHECK_INTERVAL": "10s",
+				},
+			},
+			want: RedisConfig{
+				Mode:                RedisSentinel,
+				Host:                "redis",
+				Port:                "6379",
+				Password:            "secret",
+				DB:                  3,
+				TLS:                 true,
+				SentinelAddrs:       []string{"sentinel-1:26379", "sentinel-2:26379"},
+				SentinelMaster:      "geomelody",
+				SentinelPassword:    "sentinel-secret",
+				MaxIdle:             5,
+				MaxActive:           50,
+				IdleTimeout:         2 * time.Minute,
+				ConnectTimeout:      200 * time.Millisecond,
+				ReadTimeout:         300 * time.Millisecond,
+				WriteTimeout:        400 * time.Millisecond,
+				HealthCheckInterval: 10 * time.Second,
+			},
+		},
+		{
+			name: "should success to keep the defaults of the invalid settings",
+			vars: vars{
+				constants: map[*string]string{
+					&constants.REDIS_DB:  "first",
+					&constants.REDIS_TLS: "maybe",
+				},
+				env: map[string]string{
+					"REDIS_POOL_MAX_IDLE":         "many",
+					"REDIS_HEALTH_CHECK_INTERVAL": "60",
+				},
+			},
+			want: defaults,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			consts := []*string{&constants.REDIS_MODE, &constants.REDIS_PASSWORD, &constants.REDIS_DB, &constants.REDIS_TLS,
+				&constants.REDIS_SENTINEL_ADDRS, &constants.REDIS_SENTINEL_MASTER, &constants.REDIS_SENTINEL_PASSWORD,
+				&constants.REDIS_CLUSTER_ADDRS}
+			old := make([]string, len(consts))
+			for i, c := range consts {
+				old[i] = *c
+				*c = tCase.vars.constants[c]
+			}
+			oldHost, oldPort := constants.REDIS_HOST, constants.REDIS_PORT
+			constants.REDIS_HOST, constants.REDIS_PORT = "redis", "6379"
+			defer func() {
+				for i, c := range consts {
+					*c = old[i]
+				}
+				constants.REDIS_HOST, constants.REDIS_PORT = oldHost, oldPort
+			}()
+			for _, k := range []string{"REDIS_POOL_MAX_IDLE", "REDIS_POOL_MAX_ACTIVE", "REDIS_POOL_IDLE_TIMEOUT",
+				"REDIS_CONNECT_TIMEOUT", "REDIS_READ_TIMEOUT", "REDIS_WRITE_TIMEOUT", "REDIS_HEALTH_CHECK_INTERVAL"} {
+				t.Setenv(k, tCase.vars.env[k])
+			}
+
+			// Run test
+			got := RedisConfigFromEnv()
+
+			// Assert
+			assert.Equalf(t, tCase.want, got, "case: %v", tCase.name)
+		})
+	}
+}
+
+func TestStandaloneBackend_Dial(t *testing.T) {
+	type vars struct {
+		password string
+		db       int
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want []string
+	}{
+		{
+			name: "should success to dial without AUTH nor SELECT",
+			vars: vars{},
+			want: []string{"GET top-track"},
+		},
+		{
+			name: "should success to authenticate and select the database on dial",
+			vars: vars{password: "secret", db: 3},
+			want: []string{"AUTH secret", "SELECT 3", "GET top-track"},
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			f := &fakeRedis{role: "master"}
+			cfg := testRedisConfig(RedisStandalone)
+			cfg.Host, cfg.Port, _ = net.SplitHostPort(startFakeRedis(t, f, nil))
+			cfg.Password, cfg.DB = tCase.vars.password, tCase.vars.db
+			backend, err := newRedisBackend(cfg)
+			if !assert.NoErrorf(t, err, "case: %v", tCase) {
+				return
+			}
+			defer func() {
+				_ = backend.Close()
+			}()
+
+			// Run test
+			conn, err := backend.Get()
+			if !assert.NoErrorf(t, err, "case: %v", tCase) {
+				return
+			}
+			_, _ = GetData(conn, "top-track")
+			_ = conn.Close()
+
+			// Assert
+			f.mu.Lock()
+			assert.Equalf(t, tCase.want, f.commands, "case: %v", tCase)
+			f.mu.Unlock()
+		})
+	}
+}
+
+func TestRedisConfig_newPoolHealthCheck(t *testing.T) {
+	// Setup
+	f := &fakeRedis{role: "master"}
+	cfg := testRedisConfig(RedisStandalone)
+	cfg.Host, cfg.Port, _ = net.SplitHostPort(startFakeRedis(t, f, nil))
+	cfg.HealthCheckInterval = 50 * time.Millisecond
+	backend, err := newRedisBackend(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = backend.Close()
+	}()
+	borrow := func() {
+		conn, err := backend.Get()
+		if assert.NoError(t, err) {
+			_ = conn.Close()
+		}
+	}
+	pings := func() int {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		n := 0
+		for _, cmd := range f.commands {
+			if cmd == "PING" {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Run test
+	borrow()
+	borrow()
+
+	// Assert
+	assert.Equal(t, 0, pings(), "a connection idle for less than the interval should not be pinged")
+
+	// Run test
+	time.Sleep(60 * time.Millisecond)
+	borrow()
+	borrow()
+
+	// Assert
+	assert.Equal(t, 1, pings(), "a connection idle for longer than the interval should be pinged once")
+}
+
+func TestSentinelBackend_Failover(t *testing.T) {
+	// Setup
+	masterAddr, master := startFakeRedisProcess(t, "master", "")
+	replicaAddr, _ := startFakeRedisProcess(t, "slave", "")
+	sentinelAddr, _ := startFakeRedisProcess(t, "sentinel", "geomelody", masterAddr, replicaAddr)
+
+	cfg := testRedisConfig(RedisSentinel)
+	cfg.SentinelAddrs = []string{"127.0.0.1:1", sentinelAddr}
+	cfg.SentinelMaster = "geomelody"
+	backend, err := newRedisBackend(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = backend.Close()
+	}()
+
+	setAndGet := func(val string) (string, error) {
+		conn, err := backend.Get()
+		if err != nil {
+			return "", err
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		if _, err = SetData(conn, "top-track", val, 60); err != nil {
+			return "", err
+		}
+		return GetData(conn, "top-track")
+	}
+
+	// Run test
+	got, err := setAndGet("before failover")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "before failover", got)
+	assert.Equal(t, masterAddr, backend.(*sentinelBackend).masterAddr)
+
+	// Run test
+	_ = master.Process.Kill()
+	_ = master.Wait()
+	// The idle connection to the dead master is not checked on borrow before the health check interval, so the first
+	// call fails and drops it, and the next one dials the promoted replica.
+	_, staleErr := setAndGet("after failover")
+	got, err = setAndGet("after failover")
+
+	// Assert
+	assert.Error(t, staleErr)
+	assert.NoError(t, err)
+	assert.Equal(t, "after failover", got)
+	assert.Equal(t, replicaAddr, backend.(*sentinelBackend).masterAddr)
+}
+
+func TestSentinelBackend_DropsDemotedMaster(t *testing.T) {
+	// Setup
+	node := &fakeRedis{role: "master"}
+	nodeAddr := startFakeRedis(t, node, nil)
+	sentinelAddr := startFakeRedis(t, &fakeRedis{role: "sentinel", masterName: "geomelody", candidates: []string{nodeAddr}}, nil)
+
+	cfg := testRedisConfig(RedisSentinel)
+	cfg.SentinelAddrs = []string{sentinelAddr}
+	cfg.SentinelMaster = "geomelody"
+	backend := newSentinelBackend(cfg)
+	defer func() {
+		_ = backend.Close()
+	}()
+
+	conn, err := backend.Get()
+	if !assert.NoError(t, err) {
+		return
+	}
+	_ = conn.Close()
+
+	// Run test
+	node.mu.Lock()
+	node.role = "slave"
+	node.mu.Unlock()
+	conn, err = backend.Get()
+	if !assert.NoError(t, err, "the idle connection should not be checked on borrow before the health check interval") {
+		return
+	}
+	_, setErr := SetData(conn, "top-track", "Kesariya", 60)
+	connErr := conn.Err()
+	_ = conn.Close()
+	_, err = backend.Get()
+
+	// Assert
+	assert.Error(t, setErr)
+	if assert.Error(t, connErr, "the connection answered READONLY should be broken") {
+		assert.Contains(t, connErr.Error(), "no longer the master")
+	}
+	if assert.Error(t, err, "the broken connection should be dropped, and the new one should check the role on dial") {
+		assert.Contains(t, err.Error(), "not the master")
+	}
+}
+
+func TestSentinelBackend_RoleCheckInterval(t *testing.T) {
+	type vars struct {
+		healthCheckInterval time.Duration
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		wantRoles int
+	}{
+		{
+			name:      "should only check the role on dial within the health check interval",
+			vars:      vars{healthCheckInterval: time.Minute},
+			wantRoles: 1,
+		},
+		{
+			name:      "should check the role of the idle connections on borrow once the health check interval is over",
+			vars:      vars{healthCheckInterval: time.Nanosecond},
+			wantRoles: 3,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			node := &fakeRedis{role: "master"}
+			nodeAddr := startFakeRedis(t, node, nil)
+			sentinelAddr := startFakeRedis(t, &fakeRedis{role: "sentinel", masterName: "geomelody", candidates: []string{nodeAddr}}, nil)
+
+			cfg := testRedisConfig(RedisSentinel)
+			cfg.SentinelAddrs = []string{sentinelAddr}
+			cfg.SentinelMaster = "geomelody"
+			cfg.HealthCheckInterval = tCase.vars.healthCheckInterval
+			backend := newSentinelBackend(cfg)
+			defer func() {
+				_ = backend.Close()
+			}()
+
+			// Run test
+			for i := 0; i < 3; i++ {
+				conn, err := backend.Get()
+				if !assert.NoErrorf(t, err, "case: %v", tCase) {
+					return
+				}
+				time.Sleep(time.Millisecond)
+				_ = conn.Close()
+			}
+
+			// Assert
+			node.mu.Lock()
+			roles := 0
+			for _, cmd := range node.commands {
+				if cmd == "ROLE" {
+					roles++
+				}
+			}
+			node.mu.Unlock()
+			assert.Equalf(t, tCase.wantRoles, roles, "case: %v", tCase)
+		})
+	}
+}
+
+func TestClusterBackend_Routing(t *testing.T) {
+	// Setup
+	lnA, _ := net.Listen("tcp", "127.0.0.1:0")
+	lnB, _ := net.Listen("tcp", "127.0.0.1:0")
+	addrA, addrB := lnA.Addr().String(), lnB.Addr().String()
+	resharded := []fakeSlots{{start: 0, end: 8191, addr: addrA}, {start: 8192, end: 16383, addr: addrB}}
+	// Node A still advertises the slots before resharding, so that the client has to follow the MOVED redirections.
+	nodeA := &fakeRedis{role: "master", self: addrA, slots: []fakeSlots{{start: 0, end: 16383, addr: addrA}}}
+	nodeB := &fakeRedis{role: "master", self: addrB, slots: resharded}
+	startFakeRedis(t, nodeA, lnA)
+	startFakeRedis(t, nodeB, lnB)
+
+	cfg := testRedisConfig(RedisCluster)
+	cfg.ClusterAddrs = []string{addrA}
+	backend, err := newRedisBackend(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = backend.Close()
+	}()
+	nodeA.mu.Lock()
+	nodeA.slots = resharded
+	nodeA.mu.Unlock()
+
+	conn, _ := backend.Get()
+	// "foo" is in slot 12182 and "{user1000}.following" in slot 3443.
+	keys := map[string]*fakeRedis{"foo": nodeB, "{user1000}.following": nodeA}
+
+	for key, node := range keys {
+		// Run test
+		_, setErr := SetData(conn, key, "val-"+key, 60)
+		got, getErr := GetData(conn, key)
+
+		// Assert
+		assert.NoErrorf(t, setErr, "key: %v", key)
+		assert.NoErrorf(t, getErr, "key: %v", key)
+		assert.Equalf(t, "val-"+key, got, "key: %v", key)
+		node.mu.Lock()
+		assert.Equalf(t, "val-"+key, node.data[key], "key: %v", key)
+		node.mu.Unlock()
+	}
+	assert.Equal(t, addrB, backend.(*clusterBackend).nodeFor(keySlot("foo")))
+
+	// Run test
+	wait, err := redis.Int64(tokenBucketScript.Do(conn, "foo", 1, 1))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), wait)
+	nodeA.mu.Lock()
+	for _, cmd := range nodeA.commands {
+		assert.NotContains(t, cmd, "EVAL", "the script should be sent to the node known to own the slot")
+	}
+	nodeA.mu.Unlock()
+	nodeB.mu.Lock()
+	assert.Equal(t, 1, nodeB.evals, "the script should run on the node owning the slot")
+	nodeB.mu.Unlock()
+}
+
+func TestClusterBackend_Get(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	downAddr := ln.Addr().String()
+	_ = ln.Close()
+
+	type vars struct {
+		seeds []string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success when a seed node answers",
+			vars: vars{seeds: []string{downAddr, startFakeRedis(t, &fakeRedis{role: "master"}, nil)}},
+		},
+		{
+			name:   "should fail when no node answers",
+			vars:   vars{seeds: []string{downAddr}},
+			hasErr: true,
+			err:    "no Redis Cluster node answered",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			cfg := testRedisConfig(RedisCluster)
+			cfg.ClusterAddrs = tCase.vars.seeds
+			cfg.ConnectTimeout = 100 * time.Millisecond
+			backend := newClusterBackend(cfg)
+			defer func() {
+				_ = backend.Close()
+			}()
+
+			// Run test
+			conn, err := backend.Get()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+				assert.NotNilf(t, conn, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestCommandKey(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		cmd  string
+		args []interface{}
+
+		want   string
+		wantOK bool
+	}{
+		{name: "should read the key of a command", cmd: "GET", args: []interface{}{"foo"}, want: "foo", wantOK: true},
+		{name: "should read the key of a script run with an int key count", cmd: "EVALSHA", args: []interface{}{"sha", 1, "foo"}, want: "foo", wantOK: true},
+		{name: "should read the key of a script run with an int64 key count", cmd: "EVAL", args: []interface{}{"script", int64(1), "foo"}, want: "foo", wantOK: true},
+		{name: "should read the key of a script run with a string key count", cmd: "EVAL", args: []interface{}{"script", "1", []byte("foo")}, want: "foo", wantOK: true},
+		{name: "should not read a key of a script run without keys", cmd: "EVAL", args: []interface{}{"script", 0, "arg"}},
+		{name: "should not read a key of a keyless command", cmd: "PING"},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Run test
+			got, ok := commandKey(tCase.cmd, tCase.args)
+
+			// Assert
+			assert.Equalf(t, tCase.wantOK, ok, "case: %v", tCase)
+			assert.Equalf(t, tCase.want, got, "case: %v", tCase)
+		})
+	}
+}
+
+func TestKeySlot(t *testing.T) {
+	testCases := []struct {
+		key  string
+		want int
+	}{
+		{key: "foo", want: 12182},
+		{key: "{user1000}.following", want: 3443},
+		{key: "{user1000}.followers", want: 3443},
+		// An empty hash tag is not a hash tag, so the whole key is hashed, and only the first braces are looked at.
+		{key: "foo{}{bar}", want: 8363},
+		{key: "foo{{bar}}zap", want: 4015},
+		{key: "", want: 0},
+	}
+
+	for _, tCase := range testCases {
+		assert.Equalf(t, tCase.want, keySlot(tCase.key), "case: %v", tCase)
+	}
+}
@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RetryPolicy holds how many times a vendor call is attempted and how long to wait between the attempts.
+// The wait doubles after every attempt, starting at BaseDelay and capped at MaxDelay, with a random jitter.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var (
+	retryMu sync.RWMutex
+
+	defaultRetryPolicy = RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+	}
+
+	// retryPolicies holds the max attempts of the vendor calls, by call name, which override the default one.
+	retryPolicies = map[string]int{}
+)
+
+// SetRetryPolicy sets the default retry policy of the vendor calls.
+func SetRetryPolicy(policy RetryPolicy) {
+	retryMu.Lock()
+	defer retryMu.Unlock()
+
+	defaultRetryPolicy = policy
+}
+
+// SetRetryLimit sets the max attempts of the vendor call of the given name, e.g. "GetTrackLyrics".
+func SetRetryLimit(name string, maxAttempts int) {
+	retryMu.Lock()
+	defer retryMu.Unlock()
+
+	retryPolicies[name] = maxAttempts
+}
+
+// RetryPolicyFor returns the retry policy of the vendor call of the given name.
+func RetryPolicyFor(name string) RetryPolicy {
+	retryMu.RLock()
+	defer retryMu.RUnlock()
+
+	policy := defaultRetryPolicy
+	if maxAttempts, ok := retryPolicies[name]; ok {
+		policy.MaxAttempts = maxAttempts
+	}
+
+	return policy
+}
+
+// InitRetryPolicies reads the retry policies from env.
+// HTTP_RETRY_LIMITS lists the max attempts by call name, e.g. "GetTopTrackByCountry=5,GetTrackLyrics=1".
+// It returns error if HTTP_RETRY_LIMITS is invalid.
+func InitRetryPolicies() error {
+	policy := RetryPolicyFor("")
+	updateIntFromEnv("HTTP_RETRY_MAX_ATTEMPTS", &policy.MaxAttempts)
+	updateDurationFromEnv("HTTP_RETRY_BASE_DELAY", &policy.BaseDelay)
+	updateDurationFromEnv("HTTP_RETRY_MAX_DELAY", &policy.MaxDelay)
+	SetRetryPolicy(policy)
+
+	limits := os.Getenv("HTTP_RETRY_LIMITS")
+	if limits == "" {
+		return nil
+	}
+	for _, limit := range strings.Split(limits, ",") {
+		name, val, ok := strings.Cut(strings.TrimSpace(limit), "=")
+		maxAttempts, err := strconv.Atoi(val)
+		if !ok || name == "" || err != nil || maxAttempts < 1 {
+			return fmt.Errorf("invalid HTTP_RETRY_LIMITS entry %q, expected <call name>=<max attempts>", limit)
+		}
+		SetRetryLimit(name, maxAttempts)
+	}
+
+	return nil
+}
+
+// doWithRetry executes the request and classifies its response, retrying the transient failures of the idempotent requests.
+// It returns the response and the error of the last attempt.
+func (r *ExternalRequest) doWithRetry() (*ExternalJSONResponse, error) {
+	policy := RetryPolicyFor(r.Name)
+	if !isIdempotent(r.Type) {
+		policy.MaxAttempts = 1
+	}
+
+	ctx := r.ReqCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for attempt := 1; ; attempt++ {
+		re, err := r.doOnce()
+		if err == nil {
+			return re, nil
+		}
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil || !isRetryable(err) {
+			return re, err
+		}
+
+		delay, ok := policy.delay(attempt, re)
+		if !ok {
+			return re, err
+		}
+		log.Printf("retrying %v in %v, attempt %v of %v failed: %v", r.Name, delay, attempt, policy.MaxAttempts, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return re, err
+		case <-timer.C:
+		}
+	}
+}
+
+// doOnce executes the request once, within the rate limit of its vendor and through the circuit breaker of its name
+// and vendor host. The mocked requests skip both, so that the mock responses do not depend on the previous calls.
+// It returns the parsed response and VendorError if the response reports a failure, else the request error.
+func (r *ExternalRequest) doOnce() (*ExternalJSONResponse, error) {
+	if r.GetMockHeadersFromContext(); r.isMocked() {
+		return r.roundTrip()
+	}
+
+	ctx := r.ReqCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := waitForVendorToken(ctx, r.URL); err != nil {
+		return nil, err
+	}
+
+	breaker := circuitBreakerFor(r.Name, r.URL)
+	if err := breaker.allow(); err != nil {
+		return nil, err
+	}
+	re, err := r.roundTrip()
+	breaker.record(err)
+
+	return re, err
+}
+
+// roundTrip executes the request and classifies its response.
+// It returns the parsed response and VendorError if the response reports a failure, else the request error.
+func (r *ExternalRequest) roundTrip() (*ExternalJSONResponse, error) {
+	resp, err := r.Do()
+	if err != nil {
+		return nil, err
+	}
+	re, err := ParseAsJSON(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return re, ClassifyVendorError(r.Name, re)
+}
+
+// delay is used to compute the wait before the next attempt.
+// The Retry-After header of 429 and 503 responses is honored, and the call is not retried if it asks to wait longer than MaxDelay.
+// It returns the delay and true if the call should be retried.
+func (p RetryPolicy) delay(attempt int, re *ExternalJSONResponse) (time.Duration, bool) {
+	if re != nil && (re.StatusCode == http.StatusTooManyRequests || re.StatusCode == http.StatusServiceUnavailable) {
+		if retryAfter, ok := parseRetryAfter(http.Header(re.Headers).Get("Retry-After")); ok {
+			return retryAfter, retryAfter <= p.MaxDelay
+		}
+	}
+
+	backoff := p.BaseDelay << (attempt - 1)
+	if backoff > p.MaxDelay || backoff < p.BaseDelay {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0, true
+	}
+
+	// Equal jitter keeps at least half of the backoff while spreading the retries of concurrent requests.
+	half := backoff / 2
+
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1)), true
+}
+
+// parseRetryAfter reads the Retry-After header, given either in seconds or as an HTTP date.
+// It returns the delay and true if the header is set and valid.
+func parseRetryAfter(val string) (time.Duration, bool) {
+	if val == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(val); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(val); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// isIdempotent reports whether a request of the given method can be safely sent again.
+func isIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// isRetryable reports whether the error is transient, i.e. a network failure, a rate limit or an unavailable vendor.
+func isRetryable(err error) bool {
+	var vendorErr *VendorError
+	var urlErr *url.Error
+	if errors.As(err, &vendorErr) {
+		return vendorErr.Kind == VendorRateLimited || vendorErr.Kind == VendorUnavailable
+	} else if errors.As(err, &urlErr) {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,364 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// keepRetryPolicies restores the retry policies changed by the test once it is done.
+func keepRetryPolicies(t *testing.T) {
+	retryMu.Lock()
+	policy := defaultRetryPolicy
+	limits := retryPolicies
+	retryPolicies = map[string]int{}
+	for name, maxAttempts := range limits {
+		retryPolicies[name] = maxAttempts
+	}
+	retryMu.Unlock()
+
+	t.Cleanup(func() {
+		retryMu.Lock()
+		defaultRetryPolicy = policy
+		retryPolicies = limits
+		retryMu.Unlock()
+	})
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	type vars struct {
+		attempt int
+		status  int
+		header  string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		min, max time.Duration
+		retry    bool
+	}{
+		{
+			name:  "should wait between half and the whole base delay after the first attempt",
+			vars:  vars{attempt: 1},
+			min:   50 * time.Millisecond,
+			max:   100 * time.Millisecond,
+			retry: true,
+		},
+		{
+			name:  "should double the backoff after every attempt",
+			vars:  vars{attempt: 3},
+			min:   200 * time.Millisecond,
+			max:   400 * time.Millisecond,
+			retry: true,
+		},
+		{
+			name:  "should cap the backoff at the max delay",
+			vars:  vars{attempt: 5},
+			min:   500 * time.Millisecond,
+			max:   time.Second,
+			retry: true,
+		},
+		{
+			name:  "should cap the backoff at the max delay when it overflows",
+			vars:  vars{attempt: 70},
+			min:   500 * time.Millisecond,
+			max:   time.Second,
+			retry: true,
+		},
+		{
+			name:  "should honor the Retry-After seconds of a 429",
+			vars:  vars{attempt: 1, status: http.StatusTooManyRequests, header: "1"},
+			min:   time.Second,
+			max:   time.Second,
+			retry: true,
+		},
+		{
+			name:  "should honor the Retry-After date of a 503",
+			vars:  vars{attempt: 1, status: http.StatusServiceUnavailable, header: time.Now().Add(time.Second).UTC().Format(http.TimeFormat)},
+			min:   0,
+			max:   time.Second,
+			retry: true,
+		},
+		{
+			name:  "should not retry when the Retry-After seconds exceed the max delay",
+			vars:  vars{attempt: 1, status: http.StatusTooManyRequests, header: "120"},
+			min:   120 * time.Second,
+			max:   120 * time.Second,
+			retry: false,
+		},
+		{
+			name:  "should not retry when the Retry-After date exceeds the max delay",
+			vars:  vars{attempt: 1, status: http.StatusServiceUnavailable, header: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)},
+			min:   59 * time.Minute,
+			max:   time.Hour,
+			retry: false,
+		},
+		{
+			name:  "should ignore the Retry-After of the other statuses",
+			vars:  vars{attempt: 1, status: http.StatusInternalServerError, header: "120"},
+			min:   50 * time.Millisecond,
+			max:   100 * time.Millisecond,
+			retry: true,
+		},
+		{
+			name:  "should fall back to the backoff when the Retry-After is invalid",
+			vars:  vars{attempt: 2, status: http.StatusTooManyRequests, header: "soon"},
+			min:   100 * time.Millisecond,
+			max:   200 * time.Millisecond,
+			retry: true,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			var re *ExternalJSONResponse
+			if tCase.vars.status != 0 {
+				re = &ExternalJSONResponse{}
+				re.StatusCode = tCase.vars.status
+				re.Headers = map[string][]string{"Retry-After": {tCase.vars.header}}
+			}
+
+			// The jitter is random, so the bounds are checked over many draws.
+			for i := 0; i < 100; i++ {
+				// Run test
+				got, retry := policy.delay(tCase.vars.attempt, re)
+
+				// Assert
+				assert.Equalf(t, tCase.retry, retry, "case: %v", tCase)
+				if !assert.Truef(t, got >= tCase.min && got <= tCase.max, "case: %v, got %v", tCase, got) {
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		val string
+
+		want time.Duration
+		ok   bool
+	}{
+		{name: "should fail when the header is unset", val: ""},
+		{name: "should success to parse the seconds", val: "3", want: 3 * time.Second, ok: true},
+		{name: "should fail when the seconds are negative", val: "-3"},
+		{name: "should success to parse a past date as no wait", val: "Wed, 21 Oct 2015 07:28:00 GMT", ok: true},
+		{name: "should fail when the header is invalid", val: "in a while"},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Run test
+			got, ok := parseRetryAfter(tCase.val)
+
+			// Assert
+			assert.Equalf(t, tCase.ok, ok, "case: %v", tCase)
+			assert.Equalf(t, tCase.want, got, "case: %v", tCase)
+		})
+	}
+}
+
+func TestExternalRequest_doWithRetry(t *testing.T) {
+	keepRetryPolicies(t)
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+	SetRetryLimit("TestRetryLimit", 2)
+
+	type vars struct {
+		name     string
+		method   string
+		statuses []int
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		calls  int32
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to retry the unavailable vendor",
+			vars: vars{
+				name:     "TestRetryUnavailable",
+				method:   http.MethodGet,
+				statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			},
+			calls: 3,
+		},
+		{
+			name: "should fail once the max attempts are reached",
+			vars: vars{
+				name:     "TestRetryMaxAttempts",
+				method:   http.MethodGet,
+				statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			},
+			calls:  3,
+			hasErr: true,
+			err:    "unavailable (code 502)",
+		},
+		{
+			name: "should fail once the retry limit of the call name is reached",
+			vars: vars{
+				name:     "TestRetryLimit",
+				method:   http.MethodGet,
+				statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			},
+			calls:  2,
+			hasErr: true,
+			err:    "unavailable (code 502)",
+		},
+		{
+			name: "should fail without retrying a POST",
+			vars: vars{
+				name:     "TestRetryPost",
+				method:   http.MethodPost,
+				statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			},
+			calls:  1,
+			hasErr: true,
+			err:    "unavailable (code 503)",
+		},
+		{
+			name: "should fail without retrying a not found",
+			vars: vars{
+				name:     "TestRetryNotFound",
+				method:   http.MethodGet,
+				statuses: []int{http.StatusNotFound, http.StatusOK},
+			},
+			calls:  1,
+			hasErr: true,
+			err:    "not found (code 404)",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tCase.vars.statuses[call-1])
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			req := &ExternalRequest{
+				Name:   tCase.vars.name,
+				URL:    server.URL,
+				Type:   tCase.vars.method,
+				ReqCtx: context.Background(),
+			}
+
+			// Run test
+			_, err := req.doWithRetry()
+
+			// Assert
+			assert.Equalf(t, tCase.calls, atomic.LoadInt32(&calls), "case: %v", tCase)
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+			} else {
+				assert.NoErrorf(t, err, "case: %v", tCase)
+			}
+		})
+	}
+}
+
+func TestInitRetryPolicies(t *testing.T) {
+	type vars struct {
+		maxAttempts string
+		limits      string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want   map[string]int
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to keep the default max attempts",
+			vars: vars{},
+			want: map[string]int{"GetTrackLyrics": 3},
+		},
+		{
+			name: "should success to read the max attempts by call name",
+			vars: vars{
+				maxAttempts: "4",
+				limits:      "GetTopTrackByCountry=5, GetTrackLyrics=1",
+			},
+			want: map[string]int{"GetTopTrackByCountry": 5, "GetTrackLyrics": 1, "GetTrackID": 4},
+		},
+		{
+			name:   "should fail when the max attempts are missing",
+			vars:   vars{limits: "GetTrackLyrics"},
+			hasErr: true,
+			err:    `invalid HTTP_RETRY_LIMITS entry "GetTrackLyrics"`,
+		},
+		{
+			name:   "should fail when the call name is missing",
+			vars:   vars{limits: "=2"},
+			hasErr: true,
+			err:    `invalid HTTP_RETRY_LIMITS entry "=2"`,
+		},
+		{
+			name:   "should fail when the max attempts are not a number",
+			vars:   vars{limits: "GetTrackLyrics=1,GetTrackID=many"},
+			hasErr: true,
+			err:    `invalid HTTP_RETRY_LIMITS entry "GetTrackID=many"`,
+		},
+		{
+			name:   "should fail when the max attempts are below one",
+			vars:   vars{limits: "GetTrackLyrics=0"},
+			hasErr: true,
+			err:    `invalid HTTP_RETRY_LIMITS entry "GetTrackLyrics=0"`,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			keepRetryPolicies(t)
+			SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+			t.Setenv("HTTP_RETRY_MAX_ATTEMPTS", tCase.vars.maxAttempts)
+			t.Setenv("HTTP_RETRY_BASE_DELAY", "")
+			t.Setenv("HTTP_RETRY_MAX_DELAY", "")
+			t.Setenv("HTTP_RETRY_LIMITS", tCase.vars.limits)
+
+			// Run test
+			err := InitRetryPolicies()
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+				return
+			}
+			assert.NoErrorf(t, err, "case: %v", tCase)
+			for name, maxAttempts := range tCase.want {
+				assert.Equalf(t, maxAttempts, RetryPolicyFor(name).MaxAttempts, "case: %v, call: %v", tCase, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	// HTTPProtocolAuto negotiates HTTP/2 through TLS ALPN and falls back to HTTP/1.1.
+	HTTPProtocolAuto = "auto"
+	// HTTPProtocol1 always speaks HTTP/1.1, e.g. behind proxies which mishandle HTTP/2.
+	HTTPProtocol1 = "http1"
+	// HTTPProtocol2 prefers HTTP/2, offering only it through TLS ALPN. It is not enforced, so the vendors which ignore
+	// ALPN, or are called over plain HTTP, are still spoken to in HTTP/1.1.
+	HTTPProtocol2 = "http2"
+)
+
+// TransportConfig holds the settings of the transport used to call the vendor APIs.
+type TransportConfig struct {
+	Protocol string
+
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	IdleConnTimeout       time.Duration
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+
+	// ProxyURL is the outbound proxy, the HTTPS_PROXY, HTTP_PROXY and NO_PROXY variables are used when it is empty.
+	ProxyURL string
+	// CABundle is the path of a PEM file whose certificates are trusted along with the system ones.
+	CABundle string
+}
+
+// DefaultTransportConfig returns the transport settings used when the env does not override them.
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		Protocol:              HTTPProtocolAuto,
+		DialTimeout:           5 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 5 * time.Second,
+		IdleConnTimeout:       30 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+	}
+}
+
+// TransportConfigFromEnv reads the transport settings from env, keeping the defaults of the unset or invalid variables.
+// It returns transport config.
+func TransportConfigFromEnv() TransportConfig {
+	cfg := DefaultTransportConfig()
+
+	if val := os.Getenv("HTTP_PROTOCOL"); val != "" {
+		cfg.Protocol = strings.ToLower(val)
+	}
+	updateDurationFromEnv("HTTP_DIAL_TIMEOUT", &cfg.DialTimeout)
+	updateDurationFromEnv("HTTP_TLS_HANDSHAKE_TIMEOUT", &cfg.TLSHandshakeTimeout)
+	updateDurationFromEnv("HTTP_RESPONSE_HEADER_TIMEOUT", &cfg.ResponseHeaderTimeout)
+	updateDurationFromEnv("HTTP_IDLE_CONN_TIMEOUT", &cfg.IdleConnTimeout)
+	updateIntFromEnv("HTTP_MAX_IDLE_CONNS", &cfg.MaxIdleConns)
+	updateIntFromEnv("HTTP_MAX_IDLE_CONNS_PER_HOST", &cfg.MaxIdleConnsPerHost)
+	cfg.ProxyURL = os.Getenv("HTTP_PROXY_URL")
+	cfg.CABundle = os.Getenv("HTTP_CA_BUNDLE")
+
+	return cfg
+}
+
+// NewTransport builds the transport described by the given config.
+// It returns the transport and error if the protocol, the proxy or the CA bundle is invalid.
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	t := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS12},
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid HTTP proxy URL %q", cfg.ProxyURL)
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if cfg.CABundle != "" {
+		pool, err := loadCABundle(cfg.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+
+	switch cfg.Protocol {
+	case HTTPProtocolAuto, "":
+		t.ForceAttemptHTTP2 = true
+	case HTTPProtocol1:
+		// A non nil empty map disables the HTTP/2 upgrade.
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	case HTTPProtocol2:
+		if err := http2.ConfigureTransport(t); err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig.NextProtos = []string{http2.NextProtoTLS}
+	default:
+		return nil, fmt.Errorf("invalid HTTP protocol %q, expected one of %v, %v or %v", cfg.Protocol, HTTPProtocolAuto, HTTPProtocol1, HTTPProtocol2)
+	}
+
+	return t, nil
+}
+
+// loadCABundle reads the certificates of the given PEM file on top of the system ones.
+// It returns the cert pool and error.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CA bundle: %v", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("CA bundle does not contain any PEM certificate")
+	}
+
+	return pool, nil
+}
+
+// InitHTTPClient builds the client used to call the vendor APIs, its retry policies, circuit breakers and rate limits
+// from the env, so it must be called once the env and the constants are loaded.
+// It returns error if the transport config or the retry limits are invalid.
+func InitHTTPClient() error {
+	if err := InitRetryPolicies(); err != nil {
+		return err
+	}
+	InitCircuitBreakers()
+	InitVendorRateLimits()
+
+	cfg := TransportConfigFromEnv()
+	t, err := NewTransport(cfg)
+	if err != nil {
+		return err
+	}
+
+	transport = t
+	httpClient = &http.Client{Transport: transport}
+	log.Printf("Initialized HTTP client, protocol: %v, proxy: %v, CA bundle: %v", cfg.Protocol, cfg.ProxyURL != "", cfg.CABundle != "")
+
+	return nil
+}
+
+// updateFloatFromEnv fetches given var from env and sets it to passed float variable.
+func updateFloatFromEnv(enVar string, rVar *float64) {
+	if val := os.Getenv(enVar); val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			*rVar = f
+		} else {
+			log.Printf("Error parsing float %v: %v", enVar, err)
+		}
+	}
+}
+
+// updateIntFromEnv fetches given var from env and sets it to passed int variable.
+func updateIntFromEnv(enVar string, rVar *int) {
+	if val := os.Getenv(enVar); val != "" {
+		if i, err := strconv.Atoi(val); err == nil {
+			*rVar = i
+		} else {
+			log.Printf("Error parsing integer %v: %v", enVar, err)
+		}
+	}
+}
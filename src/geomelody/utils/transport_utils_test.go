@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportConfigFromEnv(t *testing.T) {
+	type vars struct {
+		env map[string]string
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		want TransportConfig
+	}{
+		{
+			name: "should success to keep the defaults when the env is unset",
+			vars: vars{},
+			want: DefaultTransportConfig(),
+		},
+		{
+			name: "should success to override the defaults from env",
+			vars: vars{
+				env: map[string]string{
+					"HTTP_PROTOCOL":                "HTTP1",
+					"HTTP_DIAL_TIMEOUT":            "1s",
+					"HTTP_TLS_HANDSHAKE_TIMEOUT":   "2s",
+					"HTTP_RESPONSE_HEADER_TIMEOUT": "3s",
+					"HTTP_IDLE_CONN_TIMEOUT":       "1m",
+					"HTTP_MAX_IDLE_CONNS":          "20",
+					"HTTP_MAX_IDLE_CONNS_PER_HOST": "2",
+					"HTTP_PROXY_URL":               "http://proxy.local:3128",
+					"HTTP_CA_BUNDLE":               "/etc/ssl/vendor.pem",
+				},
+			},
+			want: TransportConfig{
+				Protocol:              HTTPProtocol1,
+				DialTimeout:           time.Second,
+				TLSHandshakeTimeout:   2 * time.Second,
+				ResponseHeaderTimeout: 3 * time.Second,
+				IdleConnTimeout:       time.Minute,
+				MaxIdleConns:          20,
+				MaxIdleConnsPerHost:   2,
+				ProxyURL:              "http://proxy.local:3128",
+				CABundle:              "/etc/ssl/vendor.pem",
+			},
+		},
+		{
+			name: "should success to keep the defaults of the invalid variables",
+			vars: vars{
+				env: map[string]string{
+					"HTTP_DIAL_TIMEOUT":   "5",
+					"HTTP_MAX_IDLE_CONNS": "many",
+				},
+			},
+			want: DefaultTransportConfig(),
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Setup
+			for _, k := range []string{"HTTP_PROTOCOL", "HTTP_DIAL_TIMEOUT", "HTTP_TLS_HANDSHAKE_TIMEOUT",
+				"HTTP_RESPONSE_HEADER_TIMEOUT", "HTTP_IDLE_CONN_TIMEOUT", "HTTP_MAX_IDLE_CONNS",
+				"HTTP_MAX_IDLE_CONNS_PER_HOST", "HTTP_PROXY_URL", "HTTP_CA_BUNDLE"} {
+				t.Setenv(k, tCase.vars.env[k])
+			}
+
+			// Run test
+			got := TransportConfigFromEnv()
+
+			// Assert
+			assert.Equalf(t, tCase.want, got, "case: %v", tCase)
+		})
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caBundle := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caBundle, caPEM, 0o600))
+	noPEMBundle := filepath.Join(dir, "empty.pem")
+	assert.NoError(t, os.WriteFile(noPEMBundle, []byte("not a certificate"), 0o600))
+
+	type vars struct {
+		cfg TransportConfig
+	}
+
+	testCases := []struct {
+		name string
+
+		vars vars
+
+		// check asserts the built transport, nil if it is not checked.
+		check  func(t *testing.T, tr *http.Transport)
+		hasErr bool
+		err    string
+	}{
+		{
+			name: "should success to negotiate HTTP/2 in auto mode",
+			vars: vars{cfg: TransportConfig{Protocol: HTTPProtocolAuto}},
+			check: func(t *testing.T, tr *http.Transport) {
+				assert.True(t, tr.ForceAttemptHTTP2)
+				assert.Nil(t, tr.TLSNextProto)
+			},
+		},
+		{
+			name: "should success to disable HTTP/2 in http1 mode",
+			vars: vars{cfg: TransportConfig{Protocol: HTTPProtocol1}},
+			check: func(t *testing.T, tr *http.Transport) {
+				assert.False(t, tr.ForceAttemptHTTP2)
+				assert.NotNil(t, tr.TLSNextProto)
+				assert.Empty(t, tr.TLSNextProto)
+			},
+		},
+		{
+			name: "should success to only offer HTTP/2 in http2 mode",
+			vars: vars{cfg: TransportConfig{Protocol: HTTPProtocol2}},
+			check: func(t *testing.T, tr *http.Transport) {
+				assert.Equal(t, []string{"h2"}, tr.TLSClientConfig.NextProtos)
+			},
+		},
+		{
+			name:   "should fail when the protocol is invalid",
+			vars:   vars{cfg: TransportConfig{Protocol: "http3"}},
+			hasErr: true,
+			err:    `invalid HTTP protocol "http3"`,
+		},
+		{
+			name: "should success to route the requests through the proxy",
+			vars: vars{cfg: TransportConfig{ProxyURL: "http://proxy.local:3128"}},
+			check: func(t *testing.T, tr *http.Transport) {
+				proxy, err := tr.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "ws.audioscrobbler.com"}})
+				assert.NoError(t, err)
+				assert.Equal(t, "http://proxy.local:3128", proxy.String())
+			},
+		},
+		{
+			name:   "should fail when the proxy URL has no host",
+			vars:   vars{cfg: TransportConfig{ProxyURL: "proxy.local:3128"}},
+			hasErr: true,
+			err:    `invalid HTTP proxy URL "proxy.local:3128"`,
+		},
+		{
+			name:   "should fail when the proxy URL cannot be parsed",
+			vars:   vars{cfg: TransportConfig{ProxyURL: "http://proxy.local:port"}},
+			hasErr: true,
+			err:    "invalid HTTP proxy URL",
+		},
+		{
+			name: "should success to trust the certificates of the CA bundle",
+			vars: vars{cfg: TransportConfig{Protocol: HTTPProtocolAuto, CABundle: caBundle}},
+			check: func(t *testing.T, tr *http.Transport) {
+				resp, err := (&http.Client{Transport: tr}).Get(server.URL)
+				if assert.NoError(t, err) {
+					_ = resp.Body.Close()
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+				}
+			},
+		},
+		{
+			name:   "should fail when the CA bundle does not contain any PEM certificate",
+			vars:   vars{cfg: TransportConfig{CABundle: noPEMBundle}},
+			hasErr: true,
+			err:    "CA bundle does not contain any PEM certificate",
+		},
+		{
+			name:   "should fail when the CA bundle is missing",
+			vars:   vars{cfg: TransportConfig{CABundle: filepath.Join(dir, "missing.pem")}},
+			hasErr: true,
+			err:    "error reading CA bundle",
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			// Run test
+			got, err := NewTransport(tCase.vars.cfg)
+
+			// Assert
+			if tCase.hasErr {
+				if assert.Errorf(t, err, "case: %v", tCase) {
+					assert.Containsf(t, err.Error(), tCase.err, "case: %v", tCase)
+				}
+				return
+			}
+			if assert.NoErrorf(t, err, "case: %v", tCase) && tCase.check != nil {
+				tCase.check(t, got)
+			}
+		})
+	}
+}